@@ -0,0 +1,153 @@
+package apikey
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/gloonch/CarZone/middleware"
+	"github.com/gloonch/CarZone/models"
+	"github.com/gloonch/CarZone/service"
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel"
+)
+
+type APIKeyHandler struct {
+	service service.APIKeyServiceInterface
+}
+
+func NewAPIKeyHandler(service service.APIKeyServiceInterface) *APIKeyHandler {
+	return &APIKeyHandler{
+		service: service,
+	}
+}
+
+func (handler *APIKeyHandler) GetAPIKeys(w http.ResponseWriter, r *http.Request) {
+
+	tracer := otel.Tracer("apikey-handler")
+	ctx, span := tracer.Start(r.Context(), "GetAPIKeys-Handler")
+	defer span.End()
+
+	res, err := handler.service.GetAPIKeys(ctx)
+	if errors.Is(err, middleware.ErrForbidden) {
+		http.Error(w, err.Error(), http.StatusForbidden)
+
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error getting API keys: %v", err)
+
+		return
+	}
+	body, err := json.Marshal(res)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error marshalling body: %v", err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body)
+}
+
+func (handler *APIKeyHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+
+	tracer := otel.Tracer("apikey-handler")
+	ctx, span := tracer.Start(r.Context(), "CreateAPIKey-Handler")
+	defer span.End()
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Printf("Error reading body: %v", err)
+
+		return
+	}
+
+	var keyReq models.APIKeyRequest
+	err = json.Unmarshal(body, &keyReq)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Printf("Error unmarshalling body: %v", err)
+
+		return
+	}
+
+	createdKey, err := handler.service.CreateAPIKey(ctx, &keyReq)
+	if errors.Is(err, middleware.ErrForbidden) {
+		http.Error(w, err.Error(), http.StatusForbidden)
+
+		return
+	}
+	if errors.Is(err, models.ErrInvalidAPIKeyRequest) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+	if errors.Is(err, models.ErrUserNotFound) {
+		http.Error(w, "owner does not exist", http.StatusBadRequest)
+
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error creating API key: %v", err)
+
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	body, err = json.Marshal(createdKey)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error marshalling body: %v", err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_, _ = w.Write(body)
+}
+
+func (handler *APIKeyHandler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+
+	tracer := otel.Tracer("apikey-handler")
+	ctx, span := tracer.Start(r.Context(), "RevokeAPIKey-Handler")
+	defer span.End()
+
+	id := mux.Vars(r)["id"]
+
+	revokedKey, err := handler.service.RevokeAPIKey(ctx, id)
+	if errors.Is(err, middleware.ErrForbidden) {
+		http.Error(w, err.Error(), http.StatusForbidden)
+
+		return
+	}
+	if errors.Is(err, models.ErrAPIKeyNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error revoking API key: %v", err)
+
+		return
+	}
+	body, err := json.Marshal(revokedKey)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error marshalling body: %v", err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body)
+}
@@ -0,0 +1,66 @@
+package audit
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gloonch/CarZone/middleware"
+	"github.com/gloonch/CarZone/service"
+	"go.opentelemetry.io/otel"
+)
+
+type AuditHandler struct {
+	service service.AuditServiceInterface
+}
+
+func NewAuditHandler(service service.AuditServiceInterface) *AuditHandler {
+	return &AuditHandler{
+		service: service,
+	}
+}
+
+func (handler *AuditHandler) GetDenials(w http.ResponseWriter, r *http.Request) {
+
+	tracer := otel.Tracer("audit-handler")
+	ctx, span := tracer.Start(r.Context(), "GetDenials-Handler")
+	defer span.End()
+
+	query := r.URL.Query()
+	limit := 0
+	if raw := query.Get("limit"); raw != "" {
+		value, err := strconv.Atoi(raw)
+		if err != nil {
+			http.Error(w, "limit must be a number", http.StatusBadRequest)
+
+			return
+		}
+		limit = value
+	}
+
+	res, err := handler.service.GetDenials(ctx, query.Get("username"), limit)
+	if errors.Is(err, middleware.ErrForbidden) {
+		http.Error(w, err.Error(), http.StatusForbidden)
+
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error getting access denials: %v", err)
+
+		return
+	}
+	body, err := json.Marshal(res)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error marshalling body: %v", err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body)
+}
@@ -2,10 +2,15 @@ package car
 
 import (
 	"encoding/json"
+	"errors"
 	"io"
 	"log"
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
 
+	"github.com/gloonch/CarZone/middleware"
 	"github.com/gloonch/CarZone/models"
 	"github.com/gloonch/CarZone/service"
 	"github.com/gorilla/mux"
@@ -32,7 +37,15 @@ func (handler *CarHandler) GetCarByID(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	res, err := handler.service.GetCarByID(ctx, id)
+	currency := r.URL.Query().Get("currency")
+
+	res, err := handler.service.GetCarByID(ctx, id, currency)
+	if errors.Is(err, models.ErrNoExchangeRate) || errors.Is(err, models.ErrInvalidCurrency) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		log.Printf("Error getting car: %v", err)
+
+		return
+	}
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		log.Printf("Error getting car: %v", err)
@@ -65,10 +78,32 @@ func (handler *CarHandler) GetCarByBrand(w http.ResponseWriter, r *http.Request)
 	defer span.End()
 
 	//ctx := r.Context()
-	brand := r.URL.Query().Get("brand")
-	isEngine := r.URL.Query().Get("engine") == "true"
+	query := models.CarQuery{
+		Brand:     r.URL.Query().Get("brand"),
+		IsEngine:  r.URL.Query().Get("engine") == "true",
+		Currency:  r.URL.Query().Get("currency"),
+		Sort:      r.URL.Query().Get("sort"),
+		Condition: r.URL.Query().Get("condition"),
+	}
+	if err := parseUsedCarFilters(r, &query); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+	parseAttributeFilters(r, &query)
+	if err := models.ValidateCarQuery(query); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
 
-	res, err := handler.service.GetCarsByBrand(ctx, brand, isEngine)
+	res, err := handler.service.GetCarsByBrand(ctx, query)
+	if errors.Is(err, models.ErrNoExchangeRate) || errors.Is(err, models.ErrInvalidCurrency) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		log.Printf("Error getting car by brand: %v", err)
+
+		return
+	}
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		log.Printf("Error getting car by brand: %v", err)
@@ -92,6 +127,183 @@ func (handler *CarHandler) GetCarByBrand(w http.ResponseWriter, r *http.Request)
 	}
 }
 
+// parseUsedCarFilters reads the optional minMileage, maxMileage,
+// maxPreviousOwners and damaged query parameters into query.
+func parseUsedCarFilters(r *http.Request, query *models.CarQuery) error {
+	values := r.URL.Query()
+	if value := values.Get("minMileage"); value != "" {
+		mileage, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return errors.New("minMileage must be a whole number")
+		}
+		query.MinMileage = &mileage
+	}
+	if value := values.Get("maxMileage"); value != "" {
+		mileage, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return errors.New("maxMileage must be a whole number")
+		}
+		query.MaxMileage = &mileage
+	}
+	if value := values.Get("maxPreviousOwners"); value != "" {
+		owners, err := strconv.Atoi(value)
+		if err != nil {
+			return errors.New("maxPreviousOwners must be a whole number")
+		}
+		query.MaxPreviousOwners = &owners
+	}
+	if value := values.Get("damaged"); value != "" {
+		damaged, err := strconv.ParseBool(value)
+		if err != nil {
+			return errors.New("damaged must be true or false")
+		}
+		query.Damaged = &damaged
+	}
+	return nil
+}
+
+// parseAttributeFilters reads attr.{key}={value} parameters and repeated or
+// comma-separated tag parameters into query.
+func parseAttributeFilters(r *http.Request, query *models.CarQuery) {
+	for key, values := range r.URL.Query() {
+		if name, ok := strings.CutPrefix(key, "attr."); ok && len(values) > 0 {
+			if query.Attributes == nil {
+				query.Attributes = models.Attributes{}
+			}
+			query.Attributes[name] = values[0]
+		}
+	}
+	for _, value := range r.URL.Query()["tag"] {
+		query.Tags = append(query.Tags, strings.Split(value, ",")...)
+	}
+}
+
+func (handler *CarHandler) CompareCars(w http.ResponseWriter, r *http.Request) {
+
+	tracer := otel.Tracer("car-handler")
+	ctx, span := tracer.Start(r.Context(), "CompareCars-Handler")
+	defer span.End()
+
+	var ids []string
+	for _, id := range strings.Split(r.URL.Query().Get("ids"), ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	currency := r.URL.Query().Get("currency")
+	if err := models.ValidateComparisonIDs(ids); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	res, err := handler.service.CompareCars(ctx, ids, currency)
+	if errors.Is(err, models.ErrCarNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+
+		return
+	}
+	if errors.Is(err, models.ErrNoExchangeRate) || errors.Is(err, models.ErrInvalidCurrency) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		log.Printf("Error comparing cars: %v", err)
+
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error comparing cars: %v", err)
+
+		return
+	}
+	body, err := json.Marshal(res)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error marshalling body: %v", err)
+
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body)
+}
+
+// SimilarCars accepts optional weightPrice, weightFuelType, weightBrand,
+// weightYear and weightEngine query parameters to override the default
+// weights, and limit to cap the number of results.
+func (handler *CarHandler) SimilarCars(w http.ResponseWriter, r *http.Request) {
+
+	tracer := otel.Tracer("car-handler")
+	ctx, span := tracer.Start(r.Context(), "SimilarCars-Handler")
+	defer span.End()
+
+	id := mux.Vars(r)["id"]
+	query := r.URL.Query()
+
+	weights := models.DefaultSimilarityWeights
+	params := map[string]*float64{
+		"weightPrice":    &weights.Price,
+		"weightFuelType": &weights.FuelType,
+		"weightBrand":    &weights.Brand,
+		"weightYear":     &weights.Year,
+		"weightEngine":   &weights.Engine,
+	}
+	for name, weight := range params {
+		if raw := query.Get(name); raw != "" {
+			value, err := strconv.ParseFloat(raw, 64)
+			if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+				http.Error(w, name+" must be a finite number", http.StatusBadRequest)
+
+				return
+			}
+			*weight = value
+		}
+	}
+
+	limit := models.DefaultSimilarLimit
+	if raw := query.Get("limit"); raw != "" {
+		value, err := strconv.Atoi(raw)
+		if err != nil {
+			http.Error(w, "limit must be a number", http.StatusBadRequest)
+
+			return
+		}
+		limit = value
+	}
+	if err := models.ValidateSimilarityWeights(weights); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+	if err := models.ValidateSimilarLimit(limit); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	res, err := handler.service.SimilarCars(ctx, id, weights, limit)
+	if errors.Is(err, models.ErrCarNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error getting similar cars: %v", err)
+
+		return
+	}
+	body, err := json.Marshal(res)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error marshalling body: %v", err)
+
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body)
+}
+
 func (handler *CarHandler) CreateCar(w http.ResponseWriter, r *http.Request) {
 
 	tracer := otel.Tracer("car-handler")
@@ -117,6 +329,12 @@ func (handler *CarHandler) CreateCar(w http.ResponseWriter, r *http.Request) {
 	}
 
 	createdCar, err := handler.service.CreateCar(ctx, &carReq)
+	if errors.Is(err, middleware.ErrForbidden) {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		log.Printf("Error creating car: %v", err)
+
+		return
+	}
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		log.Printf("Error creating car: %v", err)
@@ -163,6 +381,18 @@ func (handler *CarHandler) UpdateCar(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	updatedCar, err := handler.service.UpdateCar(ctx, id, &carReq)
+	if errors.Is(err, middleware.ErrForbidden) {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		log.Printf("Error updating car: %v", err)
+
+		return
+	}
+	if errors.Is(err, models.ErrOdometerDecrease) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		log.Printf("Error updating car: %v", err)
+
+		return
+	}
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		log.Printf("Error updating car: %v", err)
@@ -181,6 +411,64 @@ func (handler *CarHandler) UpdateCar(w http.ResponseWriter, r *http.Request) {
 	_, _ = w.Write(body)
 }
 
+// GetOdometerAudit lists the admin overrides of a car's odometer reading.
+func (handler *CarHandler) GetOdometerAudit(w http.ResponseWriter, r *http.Request) {
+
+	tracer := otel.Tracer("car-handler")
+	ctx, span := tracer.Start(r.Context(), "GetOdometerAudit-Handler")
+	defer span.End()
+
+	id := mux.Vars(r)["id"]
+
+	res, err := handler.service.GetOdometerAudit(ctx, id)
+	if errors.Is(err, middleware.ErrForbidden) {
+		http.Error(w, err.Error(), http.StatusForbidden)
+
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error getting odometer audit: %v", err)
+
+		return
+	}
+	body, err := json.Marshal(res)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error marshalling body: %v", err)
+
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body)
+}
+
+func (handler *CarHandler) GetTags(w http.ResponseWriter, r *http.Request) {
+
+	tracer := otel.Tracer("car-handler")
+	ctx, span := tracer.Start(r.Context(), "GetTags-Handler")
+	defer span.End()
+
+	res, err := handler.service.GetTags(ctx)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error getting tags: %v", err)
+
+		return
+	}
+	body, err := json.Marshal(res)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error marshalling body: %v", err)
+
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body)
+}
+
 func (handler *CarHandler) DeleteCar(w http.ResponseWriter, r *http.Request) {
 
 	tracer := otel.Tracer("car-handler")
@@ -192,6 +480,12 @@ func (handler *CarHandler) DeleteCar(w http.ResponseWriter, r *http.Request) {
 	id := params["id"]
 
 	deletedCar, err := handler.service.DeleteCar(ctx, id)
+	if errors.Is(err, middleware.ErrForbidden) {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		log.Printf("Error deleting car: %v", err)
+
+		return
+	}
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		log.Printf("Error deleting car: %v", err)
@@ -0,0 +1,117 @@
+package exchangerate
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/gloonch/CarZone/models"
+	"github.com/gloonch/CarZone/service"
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel"
+)
+
+type ExchangeRateHandler struct {
+	service service.ExchangeRateServiceInterface
+}
+
+func NewExchangeRateHandler(service service.ExchangeRateServiceInterface) *ExchangeRateHandler {
+	return &ExchangeRateHandler{
+		service: service,
+	}
+}
+
+func (handler *ExchangeRateHandler) GetExchangeRates(w http.ResponseWriter, r *http.Request) {
+
+	tracer := otel.Tracer("exchange-rate-handler")
+	ctx, span := tracer.Start(r.Context(), "GetExchangeRates-Handler")
+	defer span.End()
+
+	res, err := handler.service.GetExchangeRates(ctx)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error getting exchange rates: %v", err)
+
+		return
+	}
+	body, err := json.Marshal(res)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error marshalling body: %v", err)
+
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body)
+}
+
+func (handler *ExchangeRateHandler) CreateExchangeRate(w http.ResponseWriter, r *http.Request) {
+
+	tracer := otel.Tracer("exchange-rate-handler")
+	ctx, span := tracer.Start(r.Context(), "CreateExchangeRate-Handler")
+	defer span.End()
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Printf("Error reading body: %v", err)
+
+		return
+	}
+
+	var rateReq models.ExchangeRateRequest
+	err = json.Unmarshal(body, &rateReq)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Printf("Error unmarshalling body: %v", err)
+
+		return
+	}
+
+	createdRate, err := handler.service.CreateExchangeRate(ctx, &rateReq)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		log.Printf("Error creating exchange rate: %v", err)
+
+		return
+	}
+	body, err = json.Marshal(createdRate)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error marshalling body: %v", err)
+
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_, _ = w.Write(body)
+}
+
+func (handler *ExchangeRateHandler) DeleteExchangeRate(w http.ResponseWriter, r *http.Request) {
+
+	tracer := otel.Tracer("exchange-rate-handler")
+	ctx, span := tracer.Start(r.Context(), "DeleteExchangeRate-Handler")
+	defer span.End()
+
+	id := mux.Vars(r)["id"]
+
+	deletedRate, err := handler.service.DeleteExchangeRate(ctx, id)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error deleting exchange rate: %v", err)
+
+		return
+	}
+	body, err := json.Marshal(deletedRate)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error marshalling body: %v", err)
+
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body)
+}
@@ -0,0 +1,142 @@
+package favorite
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/gloonch/CarZone/middleware"
+	"github.com/gloonch/CarZone/models"
+	"github.com/gloonch/CarZone/service"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel"
+)
+
+type FavoriteHandler struct {
+	service service.FavoriteServiceInterface
+}
+
+func NewFavoriteHandler(service service.FavoriteServiceInterface) *FavoriteHandler {
+	return &FavoriteHandler{
+		service: service,
+	}
+}
+
+func (handler *FavoriteHandler) GetFavorites(w http.ResponseWriter, r *http.Request) {
+
+	tracer := otel.Tracer("favorite-handler")
+	ctx, span := tracer.Start(r.Context(), "GetFavorites-Handler")
+	defer span.End()
+
+	res, err := handler.service.GetFavorites(ctx)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error getting favorites: %v", err)
+
+		return
+	}
+	body, err := json.Marshal(res)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error marshalling body: %v", err)
+
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body)
+}
+
+func (handler *FavoriteHandler) AddFavorite(w http.ResponseWriter, r *http.Request) {
+
+	tracer := otel.Tracer("favorite-handler")
+	ctx, span := tracer.Start(r.Context(), "AddFavorite-Handler")
+	defer span.End()
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Printf("Error reading body: %v", err)
+
+		return
+	}
+
+	var favoriteReq models.FavoriteRequest
+	err = json.Unmarshal(body, &favoriteReq)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Printf("Error unmarshalling body: %v", err)
+
+		return
+	}
+
+	if err := models.ValidateFavoriteRequest(favoriteReq); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	favorite, err := handler.service.AddFavorite(ctx, &favoriteReq)
+	if errors.Is(err, middleware.ErrUnauthenticated) {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+
+		return
+	}
+	if errors.Is(err, models.ErrCarNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error adding favorite: %v", err)
+
+		return
+	}
+	body, err = json.Marshal(favorite)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error marshalling body: %v", err)
+
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_, _ = w.Write(body)
+}
+
+func (handler *FavoriteHandler) RemoveFavorite(w http.ResponseWriter, r *http.Request) {
+
+	tracer := otel.Tracer("favorite-handler")
+	ctx, span := tracer.Start(r.Context(), "RemoveFavorite-Handler")
+	defer span.End()
+
+	carID := mux.Vars(r)["carId"]
+	if _, err := uuid.Parse(carID); err != nil {
+		http.Error(w, "invalid car id", http.StatusBadRequest)
+
+		return
+	}
+
+	err := handler.service.RemoveFavorite(ctx, carID)
+	if errors.Is(err, middleware.ErrUnauthenticated) {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+
+		return
+	}
+	if errors.Is(err, models.ErrFavoriteNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error removing favorite: %v", err)
+
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,40 @@
+package jwks
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/gloonch/CarZone/models"
+)
+
+// KeyPublisher lists the public token verification keys; *middleware.KeySet
+// implements it.
+type KeyPublisher interface {
+	JWKS() models.JWKS
+}
+
+type JWKSHandler struct {
+	keys KeyPublisher
+}
+
+func NewJWKSHandler(keys KeyPublisher) *JWKSHandler {
+	return &JWKSHandler{
+		keys: keys,
+	}
+}
+
+// GetJWKS serves the public keys so other services can verify CarZone
+// tokens. Clients may cache the response for five minutes.
+func (handler *JWKSHandler) GetJWKS(w http.ResponseWriter, r *http.Request) {
+	body, err := json.Marshal(handler.keys.JWKS())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error marshalling body: %v", err)
+
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	_, _ = w.Write(body)
+}
@@ -2,15 +2,44 @@ package login
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
 	"log"
+	"math"
 	"net/http"
+	"strconv"
 	"time"
 
+	"github.com/gloonch/CarZone/middleware"
 	"github.com/gloonch/CarZone/models"
-	"github.com/golang-jwt/jwt/v4"
+	"github.com/gloonch/CarZone/service"
+	"github.com/gloonch/CarZone/throttle"
+	"github.com/gorilla/mux"
 )
 
-func LoginHandler(w http.ResponseWriter, r *http.Request) {
+type LoginHandler struct {
+	users    service.UserServiceInterface
+	sessions service.SessionServiceInterface
+	sso      service.SSOServiceInterface
+	throttle *throttle.LoginThrottle
+}
+
+// NewLoginHandler returns the login handler. sso may be nil when no OIDC
+// issuer is configured.
+func NewLoginHandler(users service.UserServiceInterface, sessions service.SessionServiceInterface, sso service.SSOServiceInterface, throttle *throttle.LoginThrottle) *LoginHandler {
+	return &LoginHandler{
+		users:    users,
+		sessions: sessions,
+		sso:      sso,
+		throttle: throttle,
+	}
+}
+
+func setRetryAfter(w http.ResponseWriter, wait time.Duration) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+}
+
+func (handler *LoginHandler) Login(w http.ResponseWriter, r *http.Request) {
 	var credentials models.Credentials
 
 	if err := json.NewDecoder(r.Body).Decode(&credentials); err != nil {
@@ -19,37 +48,177 @@ func LoginHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	valid := (credentials.UserName == "admin" && credentials.Password == "admin123")
-	if !valid {
-		http.Error(w, "Invalid username or password", http.StatusBadRequest)
+	userReq := models.UserRequest{Username: credentials.UserName}
+	userReq.Normalize()
+	ip := handler.throttle.ClientIP(r)
+	if wait := handler.throttle.Allow(userReq.Username, ip); wait > 0 {
+		setRetryAfter(w, wait)
+		http.Error(w, "Too many failed login attempts; try again later", http.StatusTooManyRequests)
+
+		return
+	}
+
+	user, err := handler.users.Authenticate(r.Context(), credentials)
+	if errors.Is(err, models.ErrInvalidCredentials) {
+		if wait := handler.throttle.Failure(userReq.Username, ip); wait > 0 {
+			setRetryAfter(w, wait)
+			log.Printf("Throttling logins for %q from %s for %s", userReq.Username, ip, wait)
+		}
+		http.Error(w, "Invalid username or password", http.StatusUnauthorized)
+
+		return
+	}
+	if err != nil {
+		handler.throttle.Cancel(userReq.Username, ip)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		log.Printf("Error authenticating user: %v", err)
+
+		return
+	}
+	handler.throttle.Success(userReq.Username, ip)
+
+	tokens, err := handler.sessions.StartSession(r.Context(), *user)
+	if err != nil {
+		http.Error(w, "Error generating token", http.StatusInternalServerError)
+		log.Printf("Error generating token: %v", err)
+
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Authorization", "Bearer "+tokens.AccessToken)
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(tokens)
+}
+
+// LoginOIDC exchanges an ID token from the configured OIDC issuer for
+// CarZone tokens.
+func (handler *LoginHandler) LoginOIDC(w http.ResponseWriter, r *http.Request) {
+	if handler.sso == nil {
+		http.Error(w, "Single sign-on is not configured", http.StatusNotFound)
+
+		return
+	}
+
+	var loginReq models.OIDCLoginRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&loginReq); err != nil || loginReq.IDToken == "" {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+
+		return
+	}
+
+	user, err := handler.sso.Login(r.Context(), &loginReq)
+	switch {
+	case errors.Is(err, models.ErrInvalidIDToken):
+		http.Error(w, models.ErrInvalidIDToken.Error(), http.StatusUnauthorized)
+		log.Printf("Rejected ID token: %v", err)
+
+		return
+	case errors.Is(err, models.ErrNoRole):
+		http.Error(w, err.Error(), http.StatusForbidden)
+
+		return
+	case errors.Is(err, models.ErrUsernameTaken):
+		http.Error(w, err.Error(), http.StatusConflict)
+
+		return
+	case errors.Is(err, models.ErrIdentityProviderUnavailable):
+		http.Error(w, models.ErrIdentityProviderUnavailable.Error(), http.StatusBadGateway)
+		log.Printf("Error verifying ID token: %v", err)
+
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		log.Printf("Error logging in with OIDC: %v", err)
+
+		return
 	}
 
-	tokenString, err := GenerateToken(credentials.UserName)
+	tokens, err := handler.sessions.StartSession(r.Context(), *user)
 	if err != nil {
 		http.Error(w, "Error generating token", http.StatusInternalServerError)
-		log.Println("Error generating token")
+		log.Printf("Error generating token: %v", err)
 
 		return
 	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Authorization", "Bearer "+tokens.AccessToken)
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(tokens)
+}
+
+func (handler *LoginHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var refreshReq models.RefreshRequest
 
-	response := map[string]string{"token": tokenString}
+	if err := json.NewDecoder(r.Body).Decode(&refreshReq); err != nil || refreshReq.RefreshToken == "" {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+
+		return
+	}
+
+	tokens, err := handler.sessions.Refresh(r.Context(), refreshReq.RefreshToken)
+	if errors.Is(err, models.ErrInvalidRefreshToken) {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error refreshing token", http.StatusInternalServerError)
+		log.Printf("Error refreshing token: %v", err)
+
+		return
+	}
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
-	w.Header().Set("Authorization", "Bearer "+tokenString)
+	w.Header().Set("Authorization", "Bearer "+tokens.AccessToken)
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(tokens)
+}
+
+func (handler *LoginHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	var logoutReq models.LogoutRequest
+
+	// The body is optional.
+	err := json.NewDecoder(r.Body).Decode(&logoutReq)
+	if err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+
+		return
+	}
+
+	err = handler.sessions.Logout(r.Context(), logoutReq.AllSessions)
+	if errors.Is(err, models.ErrNoSession) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error logging out: %v", err)
+
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
 }
 
-func GenerateToken(username string) (string, error) {
-	expiration := time.Now().Add(24 * time.Hour)
-	claims := &jwt.StandardClaims{
-		ExpiresAt: expiration.Unix(),
-		IssuedAt:  time.Now().Unix(),
-		Subject:   username,
+func (handler *LoginHandler) RevokeUserSessions(w http.ResponseWriter, r *http.Request) {
+	username := mux.Vars(r)["username"]
+
+	err := handler.sessions.RevokeUserSessions(r.Context(), username)
+	if errors.Is(err, middleware.ErrForbidden) {
+		http.Error(w, err.Error(), http.StatusForbidden)
+
+		return
 	}
+	if errors.Is(err, models.ErrUserNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	signedToken, err := token.SignedString([]byte("seycreyt"))
+		return
+	}
 	if err != nil {
-		return "", err
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error revoking sessions: %v", err)
+
+		return
 	}
-	return signedToken, nil
+	w.WriteHeader(http.StatusNoContent)
 }
@@ -0,0 +1,188 @@
+package media
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/gloonch/CarZone/blob"
+	"github.com/gloonch/CarZone/middleware"
+	"github.com/gloonch/CarZone/models"
+	"github.com/gloonch/CarZone/service"
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel"
+)
+
+type MediaHandler struct {
+	service service.MediaServiceInterface
+}
+
+func NewMediaHandler(service service.MediaServiceInterface) *MediaHandler {
+	return &MediaHandler{
+		service: service,
+	}
+}
+
+func (handler *MediaHandler) GetMediaByCar(w http.ResponseWriter, r *http.Request) {
+
+	tracer := otel.Tracer("media-handler")
+	ctx, span := tracer.Start(r.Context(), "GetMediaByCar-Handler")
+	defer span.End()
+
+	carID := mux.Vars(r)["id"]
+
+	res, err := handler.service.GetMediaByCar(ctx, carID)
+	if err != nil {
+		w.WriteHeader(mediaErrorStatus(err))
+		log.Printf("Error getting media: %v", err)
+
+		return
+	}
+	body, err := json.Marshal(res)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error marshalling body: %v", err)
+
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body)
+}
+
+// UploadMedia expects a multipart form with the attachment in the "file"
+// field.
+func (handler *MediaHandler) UploadMedia(w http.ResponseWriter, r *http.Request) {
+
+	tracer := otel.Tracer("media-handler")
+	ctx, span := tracer.Start(r.Context(), "UploadMedia-Handler")
+	defer span.End()
+
+	carID := mux.Vars(r)["id"]
+
+	r.Body = http.MaxBytesReader(w, r.Body, models.MaxMediaSize+1<<20)
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, "file is required and must be at most "+strconv.Itoa(models.MaxMediaSize)+" bytes", http.StatusBadRequest)
+		log.Printf("Error reading upload: %v", err)
+
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, models.MaxMediaSize+1))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Printf("Error reading upload: %v", err)
+
+		return
+	}
+
+	createdMedia, err := handler.service.UploadMedia(ctx, carID, header.Filename, data)
+	if err != nil {
+		status := mediaErrorStatus(err)
+		if status == http.StatusInternalServerError {
+			w.WriteHeader(status)
+		} else {
+			http.Error(w, err.Error(), status)
+		}
+		log.Printf("Error uploading media: %v", err)
+
+		return
+	}
+	body, err := json.Marshal(createdMedia)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error marshalling body: %v", err)
+
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_, _ = w.Write(body)
+}
+
+func (handler *MediaHandler) GetMedia(w http.ResponseWriter, r *http.Request) {
+	handler.serveMedia(w, r, false)
+}
+
+func (handler *MediaHandler) GetThumbnail(w http.ResponseWriter, r *http.Request) {
+	handler.serveMedia(w, r, true)
+}
+
+func (handler *MediaHandler) serveMedia(w http.ResponseWriter, r *http.Request, thumbnail bool) {
+
+	tracer := otel.Tracer("media-handler")
+	ctx, span := tracer.Start(r.Context(), "GetMedia-Handler")
+	defer span.End()
+
+	vars := mux.Vars(r)
+
+	media, content, err := handler.service.OpenMedia(ctx, vars["id"], vars["mediaId"], thumbnail)
+	if err != nil {
+		w.WriteHeader(mediaErrorStatus(err))
+		log.Printf("Error getting media: %v", err)
+
+		return
+	}
+	defer content.Close()
+
+	w.Header().Set("Content-Type", media.ContentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("inline", map[string]string{"filename": media.FileName}))
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(w, content); err != nil {
+		log.Printf("Error writing response: %v", err)
+	}
+}
+
+func (handler *MediaHandler) DeleteMedia(w http.ResponseWriter, r *http.Request) {
+
+	tracer := otel.Tracer("media-handler")
+	ctx, span := tracer.Start(r.Context(), "DeleteMedia-Handler")
+	defer span.End()
+
+	vars := mux.Vars(r)
+
+	deletedMedia, err := handler.service.DeleteMedia(ctx, vars["id"], vars["mediaId"])
+	if err != nil {
+		status := mediaErrorStatus(err)
+		if status == http.StatusInternalServerError {
+			w.WriteHeader(status)
+		} else {
+			http.Error(w, err.Error(), status)
+		}
+		log.Printf("Error deleting media: %v", err)
+
+		return
+	}
+	body, err := json.Marshal(deletedMedia)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error marshalling body: %v", err)
+
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body)
+}
+
+// mediaErrorStatus maps an error of the media service to a response status:
+// a missing car, attachment or blob is 404, a refused upload is 400, a car
+// the user may not modify is 403 and anything else, such as a storage or database failure, is 500.
+func mediaErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, models.ErrCarNotFound), errors.Is(err, models.ErrMediaNotFound), errors.Is(err, blob.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, models.ErrInvalidMedia):
+		return http.StatusBadRequest
+	case errors.Is(err, middleware.ErrForbidden):
+		return http.StatusForbidden
+	default:
+		return http.StatusInternalServerError
+	}
+}
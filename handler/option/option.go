@@ -0,0 +1,228 @@
+package option
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/gloonch/CarZone/middleware"
+	"github.com/gloonch/CarZone/models"
+	"github.com/gloonch/CarZone/service"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel"
+)
+
+type OptionHandler struct {
+	service service.OptionServiceInterface
+}
+
+func NewOptionHandler(service service.OptionServiceInterface) *OptionHandler {
+	return &OptionHandler{
+		service: service,
+	}
+}
+
+func (handler *OptionHandler) GetPackagesForCar(w http.ResponseWriter, r *http.Request) {
+
+	tracer := otel.Tracer("option-handler")
+	ctx, span := tracer.Start(r.Context(), "GetPackagesForCar-Handler")
+	defer span.End()
+
+	carID := mux.Vars(r)["id"]
+
+	res, err := handler.service.GetPackagesForCar(ctx, carID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error getting packages: %v", err)
+
+		return
+	}
+	body, err := json.Marshal(res)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error marshalling body: %v", err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body)
+}
+
+func (handler *OptionHandler) CreatePackage(w http.ResponseWriter, r *http.Request) {
+
+	tracer := otel.Tracer("option-handler")
+	ctx, span := tracer.Start(r.Context(), "CreatePackage-Handler")
+	defer span.End()
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Printf("Error reading body: %v", err)
+
+		return
+	}
+
+	var packageReq models.OptionPackageRequest
+	err = json.Unmarshal(body, &packageReq)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Printf("Error unmarshalling body: %v", err)
+
+		return
+	}
+
+	packageReq.Normalize()
+	if err := models.ValidateOptionPackageRequest(packageReq); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	createdPackage, err := handler.service.CreatePackage(ctx, &packageReq)
+	if errors.Is(err, middleware.ErrForbidden) {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		log.Printf("Error creating package: %v", err)
+
+		return
+	}
+	if errors.Is(err, models.ErrCarNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+
+		return
+	}
+	if errors.Is(err, models.ErrPackageCodeTaken) {
+		http.Error(w, err.Error(), http.StatusConflict)
+
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error creating package: %v", err)
+
+		return
+	}
+	body, err = json.Marshal(createdPackage)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error marshalling body: %v", err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_, _ = w.Write(body)
+}
+
+func (handler *OptionHandler) DeletePackage(w http.ResponseWriter, r *http.Request) {
+
+	tracer := otel.Tracer("option-handler")
+	ctx, span := tracer.Start(r.Context(), "DeletePackage-Handler")
+	defer span.End()
+
+	id := mux.Vars(r)["id"]
+	if _, err := uuid.Parse(id); err != nil {
+		http.Error(w, models.ErrPackageNotFound.Error(), http.StatusNotFound)
+
+		return
+	}
+
+	deletedPackage, err := handler.service.DeletePackage(ctx, id)
+	if errors.Is(err, middleware.ErrForbidden) {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		log.Printf("Error deleting package: %v", err)
+
+		return
+	}
+	if errors.Is(err, models.ErrPackageNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error deleting package: %v", err)
+
+		return
+	}
+	body, err := json.Marshal(deletedPackage)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error marshalling body: %v", err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body)
+}
+
+// Configure validates a package selection for a car and returns its price;
+// currency={code} adds a converted price.
+func (handler *OptionHandler) Configure(w http.ResponseWriter, r *http.Request) {
+
+	tracer := otel.Tracer("option-handler")
+	ctx, span := tracer.Start(r.Context(), "Configure-Handler")
+	defer span.End()
+
+	carID := mux.Vars(r)["id"]
+	currency := r.URL.Query().Get("currency")
+	if _, err := uuid.Parse(carID); err != nil {
+		http.Error(w, models.ErrCarNotFound.Error(), http.StatusNotFound)
+
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Printf("Error reading body: %v", err)
+
+		return
+	}
+
+	var configReq models.ConfigurationRequest
+	err = json.Unmarshal(body, &configReq)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Printf("Error unmarshalling body: %v", err)
+
+		return
+	}
+
+	res, err := handler.service.Configure(ctx, carID, &configReq, currency)
+	if errors.Is(err, models.ErrCarNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+
+		return
+	}
+	if errors.Is(err, models.ErrInvalidConfiguration) || errors.Is(err, models.ErrNoExchangeRate) ||
+		errors.Is(err, models.ErrInvalidCurrency) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error configuring car: %v", err)
+
+		return
+	}
+	body, err = json.Marshal(res)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error marshalling body: %v", err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body)
+}
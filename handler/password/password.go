@@ -0,0 +1,168 @@
+package password
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gloonch/CarZone/middleware"
+	"github.com/gloonch/CarZone/models"
+	"github.com/gloonch/CarZone/service"
+	"github.com/gloonch/CarZone/throttle"
+	"go.opentelemetry.io/otel"
+)
+
+type PasswordHandler struct {
+	service  service.PasswordServiceInterface
+	throttle *throttle.LoginThrottle
+}
+
+// NewPasswordHandler returns the password handler. Checks of the current
+// password share the login throttle, so a stolen access token cannot be used
+// to guess the password faster than logging in would.
+func NewPasswordHandler(service service.PasswordServiceInterface, throttle *throttle.LoginThrottle) *PasswordHandler {
+	return &PasswordHandler{
+		service:  service,
+		throttle: throttle,
+	}
+}
+
+func readJSON(r *http.Request, v interface{}) error {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, v)
+}
+
+// ChangePassword handles POST /me/password. Every session of the caller is
+// revoked afterwards, so the client has to log in again.
+func (handler *PasswordHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+
+	tracer := otel.Tracer("password-handler")
+	ctx, span := tracer.Start(r.Context(), "ChangePassword-Handler")
+	defer span.End()
+
+	var changeReq models.PasswordChangeRequest
+	if err := readJSON(r, &changeReq); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Printf("Error reading body: %v", err)
+
+		return
+	}
+
+	username, err := middleware.RequireUsername(ctx)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+
+		return
+	}
+	ip := handler.throttle.ClientIP(r)
+	if wait := handler.throttle.Allow(username, ip); wait > 0 {
+		setRetryAfter(w, wait)
+		http.Error(w, "Too many failed password attempts; try again later", http.StatusTooManyRequests)
+
+		return
+	}
+
+	err = handler.service.ChangePassword(ctx, &changeReq)
+	if errors.Is(err, models.ErrInvalidCredentials) {
+		if wait := handler.throttle.Failure(username, ip); wait > 0 {
+			setRetryAfter(w, wait)
+			log.Printf("Throttling password changes for %q from %s for %s", username, ip, wait)
+		}
+		http.Error(w, "Current password is incorrect", http.StatusForbidden)
+
+		return
+	}
+	if err != nil {
+		handler.throttle.Cancel(username, ip)
+	}
+	if errors.Is(err, middleware.ErrForbidden) {
+		http.Error(w, err.Error(), http.StatusForbidden)
+
+		return
+	}
+	if isInvalidPassword(err) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error changing password: %v", err)
+
+		return
+	}
+	handler.throttle.Success(username, ip)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RequestReset handles POST /password/reset. It answers 202 whether or not
+// the account exists.
+func (handler *PasswordHandler) RequestReset(w http.ResponseWriter, r *http.Request) {
+
+	tracer := otel.Tracer("password-handler")
+	ctx, span := tracer.Start(r.Context(), "RequestReset-Handler")
+	defer span.End()
+
+	var resetReq models.PasswordResetRequest
+	if err := readJSON(r, &resetReq); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Printf("Error reading body: %v", err)
+
+		return
+	}
+
+	if err := handler.service.RequestReset(ctx, &resetReq); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error requesting password reset: %v", err)
+
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// ResetPassword handles POST /password/reset/confirm.
+func (handler *PasswordHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+
+	tracer := otel.Tracer("password-handler")
+	ctx, span := tracer.Start(r.Context(), "ResetPassword-Handler")
+	defer span.End()
+
+	var confirmation models.PasswordResetConfirmation
+	if err := readJSON(r, &confirmation); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Printf("Error reading body: %v", err)
+
+		return
+	}
+
+	err := handler.service.ResetPassword(ctx, &confirmation)
+	if errors.Is(err, models.ErrInvalidResetToken) || isInvalidPassword(err) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error resetting password: %v", err)
+
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// isInvalidPassword reports whether err rejects the new password itself.
+func isInvalidPassword(err error) bool {
+	return errors.Is(err, models.ErrPasswordTooShort) || errors.Is(err, models.ErrPasswordTooLong)
+}
+
+func setRetryAfter(w http.ResponseWriter, wait time.Duration) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+}
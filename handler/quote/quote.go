@@ -0,0 +1,122 @@
+package quote
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/gloonch/CarZone/models"
+	"github.com/gloonch/CarZone/service"
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel"
+)
+
+type QuoteHandler struct {
+	service service.QuoteServiceInterface
+}
+
+func NewQuoteHandler(service service.QuoteServiceInterface) *QuoteHandler {
+	return &QuoteHandler{
+		service: service,
+	}
+}
+
+func (handler *QuoteHandler) CreateQuote(w http.ResponseWriter, r *http.Request) {
+
+	tracer := otel.Tracer("quote-handler")
+	ctx, span := tracer.Start(r.Context(), "CreateQuote-Handler")
+	defer span.End()
+
+	carID := mux.Vars(r)["id"]
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Printf("Error reading body: %v", err)
+
+		return
+	}
+
+	var quoteReq models.QuoteRequest
+	err = json.Unmarshal(body, &quoteReq)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Printf("Error unmarshalling body: %v", err)
+
+		return
+	}
+
+	createdQuote, err := handler.service.CreateQuote(ctx, carID, &quoteReq)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		log.Printf("Error creating quote: %v", err)
+
+		return
+	}
+	body, err = json.Marshal(createdQuote)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error marshalling body: %v", err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_, _ = w.Write(body)
+}
+
+func (handler *QuoteHandler) GetQuote(w http.ResponseWriter, r *http.Request) {
+
+	tracer := otel.Tracer("quote-handler")
+	ctx, span := tracer.Start(r.Context(), "GetQuote-Handler")
+	defer span.End()
+
+	id := mux.Vars(r)["id"]
+
+	res, err := handler.service.GetQuote(ctx, id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		log.Printf("Error getting quote: %v", err)
+
+		return
+	}
+	body, err := json.Marshal(res)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error marshalling body: %v", err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body)
+}
+
+func (handler *QuoteHandler) GetMyQuotes(w http.ResponseWriter, r *http.Request) {
+
+	tracer := otel.Tracer("quote-handler")
+	ctx, span := tracer.Start(r.Context(), "GetMyQuotes-Handler")
+	defer span.End()
+
+	res, err := handler.service.GetMyQuotes(ctx)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error getting quotes: %v", err)
+
+		return
+	}
+	body, err := json.Marshal(res)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error marshalling body: %v", err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body)
+}
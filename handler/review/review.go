@@ -0,0 +1,195 @@
+package review
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/gloonch/CarZone/models"
+	"github.com/gloonch/CarZone/service"
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel"
+)
+
+type ReviewHandler struct {
+	service service.ReviewServiceInterface
+}
+
+func NewReviewHandler(service service.ReviewServiceInterface) *ReviewHandler {
+	return &ReviewHandler{
+		service: service,
+	}
+}
+
+func (handler *ReviewHandler) GetCarReviews(w http.ResponseWriter, r *http.Request) {
+
+	tracer := otel.Tracer("review-handler")
+	ctx, span := tracer.Start(r.Context(), "GetCarReviews-Handler")
+	defer span.End()
+
+	carID := mux.Vars(r)["id"]
+
+	res, err := handler.service.GetCarReviews(ctx, carID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error getting reviews: %v", err)
+
+		return
+	}
+	body, err := json.Marshal(res)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error marshalling body: %v", err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body)
+}
+
+func (handler *ReviewHandler) GetReviews(w http.ResponseWriter, r *http.Request) {
+
+	tracer := otel.Tracer("review-handler")
+	ctx, span := tracer.Start(r.Context(), "GetReviews-Handler")
+	defer span.End()
+
+	status := r.URL.Query().Get("status")
+
+	res, err := handler.service.GetReviews(ctx, status)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		log.Printf("Error getting reviews: %v", err)
+
+		return
+	}
+	body, err := json.Marshal(res)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error marshalling body: %v", err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body)
+}
+
+func (handler *ReviewHandler) CreateReview(w http.ResponseWriter, r *http.Request) {
+
+	tracer := otel.Tracer("review-handler")
+	ctx, span := tracer.Start(r.Context(), "CreateReview-Handler")
+	defer span.End()
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Printf("Error reading body: %v", err)
+
+		return
+	}
+
+	var reviewReq models.ReviewRequest
+	err = json.Unmarshal(body, &reviewReq)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Printf("Error unmarshalling body: %v", err)
+
+		return
+	}
+
+	createdReview, err := handler.service.CreateReview(ctx, &reviewReq)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		log.Printf("Error creating review: %v", err)
+
+		return
+	}
+	body, err = json.Marshal(createdReview)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error marshalling body: %v", err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_, _ = w.Write(body)
+}
+
+func (handler *ReviewHandler) ModerateReview(w http.ResponseWriter, r *http.Request) {
+
+	tracer := otel.Tracer("review-handler")
+	ctx, span := tracer.Start(r.Context(), "ModerateReview-Handler")
+	defer span.End()
+
+	id := mux.Vars(r)["id"]
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Printf("Error reading body: %v", err)
+
+		return
+	}
+
+	var moderationReq models.ModerationRequest
+	err = json.Unmarshal(body, &moderationReq)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Printf("Error unmarshalling body: %v", err)
+
+		return
+	}
+
+	updatedReview, err := handler.service.ModerateReview(ctx, id, &moderationReq)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		log.Printf("Error moderating review: %v", err)
+
+		return
+	}
+	body, err = json.Marshal(updatedReview)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error marshalling body: %v", err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body)
+}
+
+func (handler *ReviewHandler) DeleteReview(w http.ResponseWriter, r *http.Request) {
+
+	tracer := otel.Tracer("review-handler")
+	ctx, span := tracer.Start(r.Context(), "DeleteReview-Handler")
+	defer span.End()
+
+	id := mux.Vars(r)["id"]
+
+	deletedReview, err := handler.service.DeleteReview(ctx, id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		log.Printf("Error deleting review: %v", err)
+
+		return
+	}
+	body, err := json.Marshal(deletedReview)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error marshalling body: %v", err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body)
+}
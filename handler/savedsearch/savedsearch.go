@@ -0,0 +1,168 @@
+package savedsearch
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/gloonch/CarZone/models"
+	"github.com/gloonch/CarZone/service"
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel"
+)
+
+type SavedSearchHandler struct {
+	service service.SavedSearchServiceInterface
+}
+
+func NewSavedSearchHandler(service service.SavedSearchServiceInterface) *SavedSearchHandler {
+	return &SavedSearchHandler{
+		service: service,
+	}
+}
+
+func (handler *SavedSearchHandler) GetSavedSearches(w http.ResponseWriter, r *http.Request) {
+
+	tracer := otel.Tracer("saved-search-handler")
+	ctx, span := tracer.Start(r.Context(), "GetSavedSearches-Handler")
+	defer span.End()
+
+	res, err := handler.service.GetSavedSearches(ctx)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error getting saved searches: %v", err)
+
+		return
+	}
+	body, err := json.Marshal(res)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error marshalling body: %v", err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body)
+}
+
+func (handler *SavedSearchHandler) CreateSavedSearch(w http.ResponseWriter, r *http.Request) {
+
+	tracer := otel.Tracer("saved-search-handler")
+	ctx, span := tracer.Start(r.Context(), "CreateSavedSearch-Handler")
+	defer span.End()
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Printf("Error reading body: %v", err)
+
+		return
+	}
+
+	var searchReq models.SavedSearchRequest
+	err = json.Unmarshal(body, &searchReq)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Printf("Error unmarshalling body: %v", err)
+
+		return
+	}
+
+	createdSearch, err := handler.service.CreateSavedSearch(ctx, &searchReq)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		log.Printf("Error creating saved search: %v", err)
+
+		return
+	}
+	body, err = json.Marshal(createdSearch)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error marshalling body: %v", err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_, _ = w.Write(body)
+}
+
+func (handler *SavedSearchHandler) DeleteSavedSearch(w http.ResponseWriter, r *http.Request) {
+
+	tracer := otel.Tracer("saved-search-handler")
+	ctx, span := tracer.Start(r.Context(), "DeleteSavedSearch-Handler")
+	defer span.End()
+
+	id := mux.Vars(r)["id"]
+
+	deletedSearch, err := handler.service.DeleteSavedSearch(ctx, id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		log.Printf("Error deleting saved search: %v", err)
+
+		return
+	}
+	body, err := json.Marshal(deletedSearch)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error marshalling body: %v", err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body)
+}
+
+// GetNotifications returns the inbox of the current user; pass unread=true
+// to leave out notifications already marked as read.
+func (handler *SavedSearchHandler) GetNotifications(w http.ResponseWriter, r *http.Request) {
+
+	tracer := otel.Tracer("saved-search-handler")
+	ctx, span := tracer.Start(r.Context(), "GetNotifications-Handler")
+	defer span.End()
+
+	unreadOnly := r.URL.Query().Get("unread") == "true"
+
+	res, err := handler.service.GetNotifications(ctx, unreadOnly)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error getting notifications: %v", err)
+
+		return
+	}
+	body, err := json.Marshal(res)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error marshalling body: %v", err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body)
+}
+
+func (handler *SavedSearchHandler) MarkNotificationRead(w http.ResponseWriter, r *http.Request) {
+
+	tracer := otel.Tracer("saved-search-handler")
+	ctx, span := tracer.Start(r.Context(), "MarkNotificationRead-Handler")
+	defer span.End()
+
+	id := mux.Vars(r)["id"]
+
+	err := handler.service.MarkNotificationRead(ctx, id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		log.Printf("Error marking notification read: %v", err)
+
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
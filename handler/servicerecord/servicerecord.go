@@ -0,0 +1,191 @@
+package servicerecord
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gloonch/CarZone/middleware"
+	"github.com/gloonch/CarZone/models"
+	"github.com/gloonch/CarZone/service"
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel"
+)
+
+type ServiceRecordHandler struct {
+	service service.ServiceRecordServiceInterface
+}
+
+func NewServiceRecordHandler(service service.ServiceRecordServiceInterface) *ServiceRecordHandler {
+	return &ServiceRecordHandler{
+		service: service,
+	}
+}
+
+func (handler *ServiceRecordHandler) GetServiceRecords(w http.ResponseWriter, r *http.Request) {
+
+	tracer := otel.Tracer("service-record-handler")
+	ctx, span := tracer.Start(r.Context(), "GetServiceRecords-Handler")
+	defer span.End()
+
+	carID := mux.Vars(r)["id"]
+
+	res, err := handler.service.GetServiceRecords(ctx, carID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error getting service records: %v", err)
+
+		return
+	}
+	if res == nil {
+		res = []models.ServiceRecord{}
+	}
+	body, err := json.Marshal(res)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error marshalling body: %v", err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body)
+}
+
+func (handler *ServiceRecordHandler) CreateServiceRecord(w http.ResponseWriter, r *http.Request) {
+
+	tracer := otel.Tracer("service-record-handler")
+	ctx, span := tracer.Start(r.Context(), "CreateServiceRecord-Handler")
+	defer span.End()
+
+	carID := mux.Vars(r)["id"]
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Printf("Error reading body: %v", err)
+
+		return
+	}
+
+	var recordReq models.ServiceRecordRequest
+	err = json.Unmarshal(body, &recordReq)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Printf("Error unmarshalling body: %v", err)
+
+		return
+	}
+
+	createdRecord, err := handler.service.CreateServiceRecord(ctx, carID, &recordReq)
+	if errors.Is(err, middleware.ErrForbidden) {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		log.Printf("Error creating service record: %v", err)
+
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		log.Printf("Error creating service record: %v", err)
+
+		return
+	}
+	body, err = json.Marshal(createdRecord)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error marshalling body: %v", err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_, _ = w.Write(body)
+}
+
+func (handler *ServiceRecordHandler) DeleteServiceRecord(w http.ResponseWriter, r *http.Request) {
+
+	tracer := otel.Tracer("service-record-handler")
+	ctx, span := tracer.Start(r.Context(), "DeleteServiceRecord-Handler")
+	defer span.End()
+
+	vars := mux.Vars(r)
+
+	deletedRecord, err := handler.service.DeleteServiceRecord(ctx, vars["id"], vars["recordId"])
+	if errors.Is(err, middleware.ErrForbidden) {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		log.Printf("Error deleting service record: %v", err)
+
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		log.Printf("Error deleting service record: %v", err)
+
+		return
+	}
+	body, err := json.Marshal(deletedRecord)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error marshalling body: %v", err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body)
+}
+
+// GetServiceDue lists cars overdue for service. The days and distance query
+// parameters override the default interval of one year or 15,000 km.
+func (handler *ServiceRecordHandler) GetServiceDue(w http.ResponseWriter, r *http.Request) {
+
+	tracer := otel.Tracer("service-record-handler")
+	ctx, span := tracer.Start(r.Context(), "GetServiceDue-Handler")
+	defer span.End()
+
+	maxDays := models.DefaultServiceIntervalDays
+	if value := r.URL.Query().Get("days"); value != "" {
+		days, err := strconv.Atoi(value)
+		if err != nil {
+			http.Error(w, "days must be a whole number", http.StatusBadRequest)
+
+			return
+		}
+		maxDays = days
+	}
+	maxDistance := int64(models.DefaultServiceIntervalDistance)
+	if value := r.URL.Query().Get("distance"); value != "" {
+		distance, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			http.Error(w, "distance must be a whole number", http.StatusBadRequest)
+
+			return
+		}
+		maxDistance = distance
+	}
+
+	res, err := handler.service.GetServiceDue(ctx, maxDays, maxDistance)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		log.Printf("Error getting cars due for service: %v", err)
+
+		return
+	}
+	body, err := json.Marshal(res)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error marshalling body: %v", err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body)
+}
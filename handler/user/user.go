@@ -0,0 +1,103 @@
+package user
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/gloonch/CarZone/middleware"
+	"github.com/gloonch/CarZone/models"
+	"github.com/gloonch/CarZone/service"
+	"go.opentelemetry.io/otel"
+)
+
+type UserHandler struct {
+	service service.UserServiceInterface
+}
+
+func NewUserHandler(service service.UserServiceInterface) *UserHandler {
+	return &UserHandler{
+		service: service,
+	}
+}
+
+func (handler *UserHandler) GetUsers(w http.ResponseWriter, r *http.Request) {
+
+	tracer := otel.Tracer("user-handler")
+	ctx, span := tracer.Start(r.Context(), "GetUsers-Handler")
+	defer span.End()
+
+	res, err := handler.service.GetUsers(ctx)
+	if errors.Is(err, middleware.ErrForbidden) {
+		http.Error(w, err.Error(), http.StatusForbidden)
+
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error getting users: %v", err)
+
+		return
+	}
+	body, err := json.Marshal(res)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error marshalling body: %v", err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body)
+}
+
+func (handler *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
+
+	tracer := otel.Tracer("user-handler")
+	ctx, span := tracer.Start(r.Context(), "CreateUser-Handler")
+	defer span.End()
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Printf("Error reading body: %v", err)
+
+		return
+	}
+
+	var userReq models.UserRequest
+	err = json.Unmarshal(body, &userReq)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Printf("Error unmarshalling body: %v", err)
+
+		return
+	}
+
+	createdUser, err := handler.service.CreateUser(ctx, &userReq)
+	if errors.Is(err, middleware.ErrForbidden) {
+		http.Error(w, err.Error(), http.StatusForbidden)
+
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		log.Printf("Error creating user: %v", err)
+
+		return
+	}
+	body, err = json.Marshal(createdUser)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error marshalling body: %v", err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_, _ = w.Write(body)
+}
@@ -0,0 +1,171 @@
+package valuation
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/gloonch/CarZone/middleware"
+	"github.com/gloonch/CarZone/models"
+	"github.com/gloonch/CarZone/service"
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel"
+)
+
+type ValuationHandler struct {
+	service service.ValuationServiceInterface
+}
+
+func NewValuationHandler(service service.ValuationServiceInterface) *ValuationHandler {
+	return &ValuationHandler{
+		service: service,
+	}
+}
+
+// ValuateCar returns the estimated market value of a car with the breakdown
+// of adjustments; currency={code} adds a converted estimate.
+func (handler *ValuationHandler) ValuateCar(w http.ResponseWriter, r *http.Request) {
+
+	tracer := otel.Tracer("valuation-handler")
+	ctx, span := tracer.Start(r.Context(), "ValuateCar-Handler")
+	defer span.End()
+
+	carID := mux.Vars(r)["id"]
+	currency := r.URL.Query().Get("currency")
+
+	res, err := handler.service.ValuateCar(ctx, carID, currency)
+	if errors.Is(err, models.ErrNoExchangeRate) || errors.Is(err, models.ErrInvalidCurrency) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		log.Printf("Error valuating car: %v", err)
+
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		log.Printf("Error valuating car: %v", err)
+
+		return
+	}
+	body, err := json.Marshal(res)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error marshalling body: %v", err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body)
+}
+
+func (handler *ValuationHandler) GetCurves(w http.ResponseWriter, r *http.Request) {
+
+	tracer := otel.Tracer("valuation-handler")
+	ctx, span := tracer.Start(r.Context(), "GetCurves-Handler")
+	defer span.End()
+
+	res, err := handler.service.GetCurves(ctx)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error getting depreciation curves: %v", err)
+
+		return
+	}
+	body, err := json.Marshal(res)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error marshalling body: %v", err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body)
+}
+
+func (handler *ValuationHandler) SaveCurve(w http.ResponseWriter, r *http.Request) {
+
+	tracer := otel.Tracer("valuation-handler")
+	ctx, span := tracer.Start(r.Context(), "SaveCurve-Handler")
+	defer span.End()
+
+	brand := mux.Vars(r)["brand"]
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Printf("Error reading body: %v", err)
+
+		return
+	}
+
+	var curveReq models.DepreciationCurveRequest
+	err = json.Unmarshal(body, &curveReq)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Printf("Error unmarshalling body: %v", err)
+
+		return
+	}
+
+	savedCurve, err := handler.service.SaveCurve(ctx, brand, &curveReq)
+	if errors.Is(err, middleware.ErrForbidden) {
+		http.Error(w, err.Error(), http.StatusForbidden)
+
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		log.Printf("Error saving depreciation curve: %v", err)
+
+		return
+	}
+	body, err = json.Marshal(savedCurve)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error marshalling body: %v", err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body)
+}
+
+func (handler *ValuationHandler) DeleteCurve(w http.ResponseWriter, r *http.Request) {
+
+	tracer := otel.Tracer("valuation-handler")
+	ctx, span := tracer.Start(r.Context(), "DeleteCurve-Handler")
+	defer span.End()
+
+	brand := mux.Vars(r)["brand"]
+
+	deletedCurve, err := handler.service.DeleteCurve(ctx, brand)
+	if errors.Is(err, middleware.ErrForbidden) {
+		http.Error(w, err.Error(), http.StatusForbidden)
+
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		log.Printf("Error deleting depreciation curve: %v", err)
+
+		return
+	}
+	body, err := json.Marshal(deletedCurve)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error marshalling body: %v", err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body)
+}
@@ -9,15 +9,65 @@ import (
 	"os"
 	"time"
 
+	"github.com/gloonch/CarZone/blob"
 	"github.com/gloonch/CarZone/driver"
+	apiKeyHandler "github.com/gloonch/CarZone/handler/apikey"
+	auditHandler "github.com/gloonch/CarZone/handler/audit"
 	carHandler "github.com/gloonch/CarZone/handler/car"
 	engineHandler "github.com/gloonch/CarZone/handler/engine"
+	exchangeRateHandler "github.com/gloonch/CarZone/handler/exchangerate"
+	favoriteHandler "github.com/gloonch/CarZone/handler/favorite"
+	jwksHandler "github.com/gloonch/CarZone/handler/jwks"
 	loginHandler "github.com/gloonch/CarZone/handler/login"
+	mediaHandler "github.com/gloonch/CarZone/handler/media"
+	optionHandler "github.com/gloonch/CarZone/handler/option"
+	passwordHandler "github.com/gloonch/CarZone/handler/password"
+	quoteHandler "github.com/gloonch/CarZone/handler/quote"
+	reviewHandler "github.com/gloonch/CarZone/handler/review"
+	savedSearchHandler "github.com/gloonch/CarZone/handler/savedsearch"
+	serviceRecordHandler "github.com/gloonch/CarZone/handler/servicerecord"
+	userHandler "github.com/gloonch/CarZone/handler/user"
+	valuationHandler "github.com/gloonch/CarZone/handler/valuation"
 	"github.com/gloonch/CarZone/middleware"
+	"github.com/gloonch/CarZone/models"
+	"github.com/gloonch/CarZone/notify"
+	"github.com/gloonch/CarZone/oidc"
+	"github.com/gloonch/CarZone/policy"
+	"github.com/gloonch/CarZone/service"
+	apiKeyService "github.com/gloonch/CarZone/service/apikey"
+	auditService "github.com/gloonch/CarZone/service/audit"
 	carService "github.com/gloonch/CarZone/service/car"
 	engineService "github.com/gloonch/CarZone/service/engine"
+	exchangeRateService "github.com/gloonch/CarZone/service/exchangerate"
+	favoriteService "github.com/gloonch/CarZone/service/favorite"
+	mediaService "github.com/gloonch/CarZone/service/media"
+	optionService "github.com/gloonch/CarZone/service/option"
+	passwordService "github.com/gloonch/CarZone/service/password"
+	quoteService "github.com/gloonch/CarZone/service/quote"
+	reviewService "github.com/gloonch/CarZone/service/review"
+	savedSearchService "github.com/gloonch/CarZone/service/savedsearch"
+	serviceRecordService "github.com/gloonch/CarZone/service/servicerecord"
+	sessionService "github.com/gloonch/CarZone/service/session"
+	ssoService "github.com/gloonch/CarZone/service/sso"
+	userService "github.com/gloonch/CarZone/service/user"
+	valuationService "github.com/gloonch/CarZone/service/valuation"
+	apiKeyStore "github.com/gloonch/CarZone/store/apikey"
+	auditStore "github.com/gloonch/CarZone/store/audit"
 	carStore "github.com/gloonch/CarZone/store/car"
+	depreciationStore "github.com/gloonch/CarZone/store/depreciation"
 	engineStore "github.com/gloonch/CarZone/store/engine"
+	exchangeRateStore "github.com/gloonch/CarZone/store/exchangerate"
+	favoriteStore "github.com/gloonch/CarZone/store/favorite"
+	mediaStore "github.com/gloonch/CarZone/store/media"
+	optionStore "github.com/gloonch/CarZone/store/option"
+	passwordResetStore "github.com/gloonch/CarZone/store/passwordreset"
+	quoteStore "github.com/gloonch/CarZone/store/quote"
+	reviewStore "github.com/gloonch/CarZone/store/review"
+	savedSearchStore "github.com/gloonch/CarZone/store/savedsearch"
+	serviceRecordStore "github.com/gloonch/CarZone/store/servicerecord"
+	tokenStore "github.com/gloonch/CarZone/store/token"
+	userStore "github.com/gloonch/CarZone/store/user"
+	"github.com/gloonch/CarZone/throttle"
 	"github.com/gorilla/mux"
 	"github.com/joho/godotenv"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -38,11 +88,11 @@ func main() {
 
 	traceProvider, err := startTracing()
 	if err != nil {
-		log.Fatalf("Failed to start tracing: ", err)
+		log.Fatalf("Failed to start tracing: %v", err)
 	}
 	defer func() {
 		if err := traceProvider.Shutdown(context.Background()); err != nil {
-			log.Fatalf("Failed to shutdown tracing: ", err)
+			log.Fatalf("Failed to shutdown tracing: %v", err)
 		}
 	}()
 
@@ -52,14 +102,100 @@ func main() {
 	defer driver.CloseDB()
 
 	db := driver.GetDB()
+
+	keys, err := loadKeys()
+	if err != nil {
+		log.Fatalf("Error loading token signing keys: %v", err)
+	}
+
+	userStore := userStore.NewUserStore(db)
+	userService := userService.NewUserService(userStore)
+
+	ssoService, err := newSSOService(userStore)
+	if err != nil {
+		log.Fatalf("Error configuring OIDC login: %v", err)
+	}
+
+	tokenStore := tokenStore.NewTokenStore(db)
+	sessionService := sessionService.NewSessionService(tokenStore, userStore, keys)
+
+	notifier, err := newNotifier()
+	if err != nil {
+		log.Fatalf("Error configuring password reset delivery: %v", err)
+	}
+	passwordResetStore := passwordResetStore.NewPasswordResetStore(db)
+	passwordService := passwordService.NewPasswordService(userStore, passwordResetStore, tokenStore, notifier)
+
+	apiKeyStore := apiKeyStore.NewAPIKeyStore(db)
+	apiKeyService := apiKeyService.NewAPIKeyService(apiKeyStore, userStore)
+
+	auditStore := auditStore.NewAuditStore(db)
+	auditService := auditService.NewAuditService(auditStore)
+
+	exchangeRateStore := exchangeRateStore.NewExchangeRateStore(db)
+	exchangeRateService := exchangeRateService.NewExchangeRateService(exchangeRateStore)
+
+	favoriteStore := favoriteStore.NewFavoriteStore(db)
+
+	savedSearchStore := savedSearchStore.NewSavedSearchStore(db)
+	savedSearchMatcher := savedSearchService.NewMatcher(savedSearchStore, 100)
+	savedSearchService := savedSearchService.NewSavedSearchService(savedSearchStore)
+	go savedSearchMatcher.Run(context.Background())
+
 	carStore := carStore.NewStore(db)
-	carService := carService.NewCarService(carStore)
+	carPolicy := policy.NewCarPolicy(userStore)
+	carService := carService.NewCarService(carStore, exchangeRateService, favoriteStore, savedSearchMatcher, carPolicy)
+	favoriteService := favoriteService.NewFavoriteService(favoriteStore, carStore)
+
+	reviewStore := reviewStore.NewReviewStore(db)
+	reviewService := reviewService.NewReviewService(reviewStore)
+
+	serviceRecordStore := serviceRecordStore.NewServiceRecordStore(db)
+	serviceRecordService := serviceRecordService.NewServiceRecordService(serviceRecordStore, carStore, carPolicy)
+
+	depreciationStore := depreciationStore.NewDepreciationStore(db)
+	valuationService := valuationService.NewValuationService(depreciationStore, carStore, exchangeRateService)
+
+	optionStore := optionStore.NewOptionStore(db)
+	optionService := optionService.NewOptionService(optionStore, carStore, exchangeRateService, carPolicy)
+
+	quoteStore := quoteStore.NewQuoteStore(db)
+	quoteService := quoteService.NewQuoteService(quoteStore, carStore, optionStore)
 
 	engineStore := engineStore.NewEngineStore(db)
 	engineService := engineService.NewEngineService(engineStore)
 
+	mediaDir := os.Getenv("MEDIA_DIR")
+	if mediaDir == "" {
+		mediaDir = "./media"
+	}
+	blobStore, err := blob.NewLocalStore(mediaDir)
+	if err != nil {
+		log.Fatalf("Error creating media storage: %v", err)
+	}
+	mediaStore := mediaStore.NewMediaStore(db)
+	mediaService := mediaService.NewMediaService(mediaStore, carStore, blobStore, carPolicy)
+
+	loginThrottle := throttle.NewLoginThrottle(throttle.UsernamePolicy, throttle.IPPolicy)
+	loginThrottle.TrustForwardedFor = os.Getenv("TRUST_FORWARDED_FOR") == "true"
+
+	loginHandler := loginHandler.NewLoginHandler(userService, sessionService, ssoService, loginThrottle)
+	jwksHandler := jwksHandler.NewJWKSHandler(keys)
+	passwordHandler := passwordHandler.NewPasswordHandler(passwordService, loginThrottle)
+	userHandler := userHandler.NewUserHandler(userService)
+	auditHandler := auditHandler.NewAuditHandler(auditService)
+	apiKeyHandler := apiKeyHandler.NewAPIKeyHandler(apiKeyService)
 	carHandler := carHandler.NewCarHandler(carService)
 	engineHandler := engineHandler.NewEngineHandler(engineService)
+	exchangeRateHandler := exchangeRateHandler.NewExchangeRateHandler(exchangeRateService)
+	mediaHandler := mediaHandler.NewMediaHandler(mediaService)
+	favoriteHandler := favoriteHandler.NewFavoriteHandler(favoriteService)
+	savedSearchHandler := savedSearchHandler.NewSavedSearchHandler(savedSearchService)
+	reviewHandler := reviewHandler.NewReviewHandler(reviewService)
+	serviceRecordHandler := serviceRecordHandler.NewServiceRecordHandler(serviceRecordService)
+	valuationHandler := valuationHandler.NewValuationHandler(valuationService)
+	optionHandler := optionHandler.NewOptionHandler(optionService)
+	quoteHandler := quoteHandler.NewQuoteHandler(quoteService)
 
 	router := mux.NewRouter()
 
@@ -67,26 +203,105 @@ func main() {
 
 	schemaFile := "./store/schema.sql"
 	if err := executeSchemaFile(db, schemaFile); err != nil {
-		log.Fatalf("Error while executing the schema file: ", err)
+		log.Fatalf("Error while executing the schema file: %v", err)
+	}
+
+	adminUsername := os.Getenv("ADMIN_USERNAME")
+	if adminUsername == "" {
+		adminUsername = "admin"
+	}
+	if err := userService.EnsureAdmin(context.Background(), adminUsername, os.Getenv("ADMIN_PASSWORD")); err != nil {
+		log.Printf("Error creating the first admin: %v", err)
 	}
 
-	router.HandleFunc("/login", loginHandler.LoginHandler).Methods("POST")
+	router.HandleFunc("/login", loginHandler.Login).Methods("POST")
+	router.HandleFunc("/login/oidc", loginHandler.LoginOIDC).Methods("POST")
+	router.HandleFunc("/token/refresh", loginHandler.Refresh).Methods("POST")
+	router.HandleFunc("/.well-known/jwks.json", jwksHandler.GetJWKS).Methods("GET")
+	router.HandleFunc("/password/reset", passwordHandler.RequestReset).Methods("POST")
+	router.HandleFunc("/password/reset/confirm", passwordHandler.ResetPassword).Methods("POST")
 
 	// Middleware
 	protected := router.PathPrefix("/").Subrouter()
-	protected.Use(middleware.AuthMiddleware)
+	authenticator := middleware.NewAuthenticator(keys, tokenStore, apiKeyService)
+	protected.Use(authenticator.AuthMiddleware)
 	protected.Use(middleware.MetricMiddleware)
 
+	// Routes without a wrapper are open to every role, viewer included.
+	authorizer := middleware.NewAuthorizer(auditStore)
+	editor := authorizer.Require(models.RoleEditor)
+	admin := authorizer.Require(models.RoleAdmin)
+
+	protected.HandleFunc("/logout", loginHandler.Logout).Methods("POST")
+	protected.HandleFunc("/me/password", passwordHandler.ChangePassword).Methods("POST")
+
+	protected.HandleFunc("/cars/compare", carHandler.CompareCars).Methods("GET")
+	protected.HandleFunc("/cars/service-due", serviceRecordHandler.GetServiceDue).Methods("GET")
 	protected.HandleFunc("/cars/{id}", carHandler.GetCarByID).Methods("GET")
+	protected.HandleFunc("/cars/{id}/similar", carHandler.SimilarCars).Methods("GET")
 	protected.HandleFunc("/cars", carHandler.GetCarByBrand).Methods("GET")
-	protected.HandleFunc("/cars", carHandler.CreateCar).Methods("POST")
-	protected.HandleFunc("/cars/{id}", carHandler.UpdateCar).Methods("PUT")
-	protected.HandleFunc("/cars/{id}", carHandler.DeleteCar).Methods("DELETE")
+	protected.Handle("/cars", editor(http.HandlerFunc(carHandler.CreateCar))).Methods("POST")
+	protected.Handle("/cars/{id}", editor(http.HandlerFunc(carHandler.UpdateCar))).Methods("PUT")
+	protected.Handle("/cars/{id}", editor(http.HandlerFunc(carHandler.DeleteCar))).Methods("DELETE")
+	protected.Handle("/cars/{id}/odometer-audit", admin(http.HandlerFunc(carHandler.GetOdometerAudit))).Methods("GET")
+	protected.HandleFunc("/tags", carHandler.GetTags).Methods("GET")
+
+	protected.HandleFunc("/cars/{id}/images", mediaHandler.GetMediaByCar).Methods("GET")
+	protected.Handle("/cars/{id}/images", editor(http.HandlerFunc(mediaHandler.UploadMedia))).Methods("POST")
+	protected.HandleFunc("/cars/{id}/images/{mediaId}", mediaHandler.GetMedia).Methods("GET")
+	protected.HandleFunc("/cars/{id}/images/{mediaId}/thumbnail", mediaHandler.GetThumbnail).Methods("GET")
+	protected.Handle("/cars/{id}/images/{mediaId}", editor(http.HandlerFunc(mediaHandler.DeleteMedia))).Methods("DELETE")
+
+	protected.HandleFunc("/cars/{id}/service-records", serviceRecordHandler.GetServiceRecords).Methods("GET")
+	protected.Handle("/cars/{id}/service-records", editor(http.HandlerFunc(serviceRecordHandler.CreateServiceRecord))).Methods("POST")
+	protected.Handle("/cars/{id}/service-records/{recordId}", editor(http.HandlerFunc(serviceRecordHandler.DeleteServiceRecord))).Methods("DELETE")
+
+	protected.HandleFunc("/cars/{id}/valuation", valuationHandler.ValuateCar).Methods("GET")
+	protected.HandleFunc("/depreciation-curves", valuationHandler.GetCurves).Methods("GET")
+	protected.Handle("/depreciation-curves/{brand}", admin(http.HandlerFunc(valuationHandler.SaveCurve))).Methods("PUT")
+	protected.Handle("/depreciation-curves/{brand}", admin(http.HandlerFunc(valuationHandler.DeleteCurve))).Methods("DELETE")
+
+	protected.HandleFunc("/cars/{id}/packages", optionHandler.GetPackagesForCar).Methods("GET")
+	protected.HandleFunc("/cars/{id}/configure", optionHandler.Configure).Methods("POST")
+	protected.Handle("/packages", editor(http.HandlerFunc(optionHandler.CreatePackage))).Methods("POST")
+	protected.Handle("/packages/{id}", editor(http.HandlerFunc(optionHandler.DeletePackage))).Methods("DELETE")
+
+	protected.HandleFunc("/cars/{id}/quote", quoteHandler.CreateQuote).Methods("POST")
+	protected.HandleFunc("/quotes/{id}", quoteHandler.GetQuote).Methods("GET")
+	protected.HandleFunc("/me/quotes", quoteHandler.GetMyQuotes).Methods("GET")
 
 	protected.HandleFunc("/engine/{id}", engineHandler.GetEngineByID).Methods("GET")
-	protected.HandleFunc("/engine", engineHandler.CreateEngine).Methods("POST")
-	protected.HandleFunc("/engine/{id}", engineHandler.UpdateEngine).Methods("PUT")
-	protected.HandleFunc("/engine/{id}", engineHandler.DeleteEngine).Methods("DELETE")
+	protected.Handle("/engine", editor(http.HandlerFunc(engineHandler.CreateEngine))).Methods("POST")
+	protected.Handle("/engine/{id}", editor(http.HandlerFunc(engineHandler.UpdateEngine))).Methods("PUT")
+	protected.Handle("/engine/{id}", editor(http.HandlerFunc(engineHandler.DeleteEngine))).Methods("DELETE")
+
+	protected.HandleFunc("/cars/{id}/reviews", reviewHandler.GetCarReviews).Methods("GET")
+	protected.Handle("/reviews", editor(http.HandlerFunc(reviewHandler.GetReviews))).Methods("GET")
+	protected.HandleFunc("/reviews", reviewHandler.CreateReview).Methods("POST")
+	protected.Handle("/reviews/{id}/moderation", editor(http.HandlerFunc(reviewHandler.ModerateReview))).Methods("PUT")
+	protected.HandleFunc("/reviews/{id}", reviewHandler.DeleteReview).Methods("DELETE")
+
+	protected.HandleFunc("/me/favorites", favoriteHandler.GetFavorites).Methods("GET")
+	protected.HandleFunc("/me/favorites", favoriteHandler.AddFavorite).Methods("POST")
+	protected.HandleFunc("/me/favorites/{carId}", favoriteHandler.RemoveFavorite).Methods("DELETE")
+
+	protected.HandleFunc("/me/searches", savedSearchHandler.GetSavedSearches).Methods("GET")
+	protected.HandleFunc("/me/searches", savedSearchHandler.CreateSavedSearch).Methods("POST")
+	protected.HandleFunc("/me/searches/{id}", savedSearchHandler.DeleteSavedSearch).Methods("DELETE")
+	protected.HandleFunc("/me/notifications", savedSearchHandler.GetNotifications).Methods("GET")
+	protected.HandleFunc("/me/notifications/{id}/read", savedSearchHandler.MarkNotificationRead).Methods("POST")
+
+	protected.Handle("/users", admin(http.HandlerFunc(userHandler.GetUsers))).Methods("GET")
+	protected.Handle("/users", admin(http.HandlerFunc(userHandler.CreateUser))).Methods("POST")
+	protected.Handle("/users/{username}/sessions", admin(http.HandlerFunc(loginHandler.RevokeUserSessions))).Methods("DELETE")
+	protected.Handle("/api-keys", admin(http.HandlerFunc(apiKeyHandler.GetAPIKeys))).Methods("GET")
+	protected.Handle("/api-keys", admin(http.HandlerFunc(apiKeyHandler.CreateAPIKey))).Methods("POST")
+	protected.Handle("/api-keys/{id}", admin(http.HandlerFunc(apiKeyHandler.RevokeAPIKey))).Methods("DELETE")
+	protected.Handle("/audit/denials", admin(http.HandlerFunc(auditHandler.GetDenials))).Methods("GET")
+
+	protected.HandleFunc("/exchange-rates", exchangeRateHandler.GetExchangeRates).Methods("GET")
+	protected.Handle("/exchange-rates", admin(http.HandlerFunc(exchangeRateHandler.CreateExchangeRate))).Methods("POST")
+	protected.Handle("/exchange-rates/{id}", admin(http.HandlerFunc(exchangeRateHandler.DeleteExchangeRate))).Methods("DELETE")
 
 	router.Handle("/metrics", promhttp.Handler())
 
@@ -98,6 +313,65 @@ func main() {
 
 }
 
+// loadKeys reads the token keys from JWT_KEYS_DIR, signing with the key named
+// by JWT_SIGNING_KEY. Without a key directory a temporary key is generated.
+func loadKeys() (*middleware.KeySet, error) {
+	keysDir := os.Getenv("JWT_KEYS_DIR")
+	if keysDir == "" {
+		log.Println("JWT_KEYS_DIR is not set; signing tokens with a temporary key that is lost on restart")
+		return middleware.GenerateKeySet()
+	}
+	return middleware.LoadKeySet(keysDir, os.Getenv("JWT_SIGNING_KEY"))
+}
+
+// newNotifier delivers password reset tokens as files in PASSWORD_RESET_DIR,
+// or to the log when it is unset. Both are meant for development.
+func newNotifier() (service.Notifier, error) {
+	dir := os.Getenv("PASSWORD_RESET_DIR")
+	if dir == "" {
+		return notify.LogNotifier{}, nil
+	}
+	return notify.NewFileNotifier(dir)
+}
+
+// newSSOService configures login with the OIDC issuer at OIDC_ISSUER_URL. It
+// returns nil when no issuer is configured.
+func newSSOService(users *userStore.UserStore) (service.SSOServiceInterface, error) {
+	issuerURL := os.Getenv("OIDC_ISSUER_URL")
+	if issuerURL == "" {
+		return nil, nil
+	}
+	provider, err := oidc.NewProvider(oidc.Config{
+		IssuerURL:   issuerURL,
+		ClientID:    os.Getenv("OIDC_CLIENT_ID"),
+		GroupsClaim: os.Getenv("OIDC_GROUPS_CLAIM"),
+	})
+	if err != nil {
+		return nil, err
+	}
+	groupRoles, err := ssoService.ParseGroupRoles(os.Getenv("OIDC_GROUP_ROLES"))
+	if err != nil {
+		return nil, err
+	}
+	defaultRole := os.Getenv("OIDC_DEFAULT_ROLE")
+	if defaultRole == "" {
+		defaultRole = models.RoleViewer
+	}
+	if defaultRole != ssoService.NoRole {
+		if err := models.ValidateRole(defaultRole); err != nil {
+			return nil, err
+		}
+	}
+	tenantID := os.Getenv("OIDC_TENANT")
+	if tenantID == "" {
+		tenantID = models.DefaultTenant
+	}
+	if err := models.ValidateTenantID(tenantID); err != nil {
+		return nil, err
+	}
+	return ssoService.NewSSOService(provider, users, groupRoles, defaultRole, tenantID), nil
+}
+
 func executeSchemaFile(db *sql.DB, file string) error {
 	sqlFile, err := os.ReadFile(file)
 	if err != nil {
@@ -2,38 +2,184 @@ package middleware
 
 import (
 	"context"
+	"errors"
+	"log"
 	"net/http"
+	"strings"
 
+	"github.com/gloonch/CarZone/models"
 	"github.com/golang-jwt/jwt/v4"
 )
 
-var jwtKey = []byte("seycreyt")
+var (
+	ErrUnauthenticated = errors.New("an authenticated user is required")
+	ErrForbidden       = errors.New("your role does not allow this operation")
+)
 
 type Claims struct {
 	Username string `json:"username"`
+	Role     string `json:"role"`
+	// TenantID scopes every car and engine query of the request.
+	TenantID string `json:"tenant_id"`
+	// SessionID ties the token to the login session it was issued for.
+	SessionID string `json:"sid,omitempty"`
 	jwt.StandardClaims
 }
 
-func AuthMiddleware(next http.Handler) http.Handler {
+// RevocationList reports whether an access token was revoked before it
+// expired, e.g. by a logout.
+type RevocationList interface {
+	IsRevoked(ctx context.Context, jti string) (bool, error)
+}
+
+// APIKeyAuthenticator resolves an API key to the principal it acts as.
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(ctx context.Context, key string) (*models.Principal, error)
+}
+
+type Authenticator struct {
+	keys        *KeySet
+	revocations RevocationList
+	apiKeys     APIKeyAuthenticator
+}
+
+func NewAuthenticator(keys *KeySet, revocations RevocationList, apiKeys APIKeyAuthenticator) *Authenticator {
+	return &Authenticator{
+		keys:        keys,
+		revocations: revocations,
+		apiKeys:     apiKeys,
+	}
+}
+
+var errInvalidToken = errors.New("access token is missing, invalid or revoked")
+
+// AuthMiddleware accepts requests with a valid, unrevoked bearer access token
+// or an API key, sent as X-API-Key or as "Authorization: ApiKey <key>".
+// Tokens without a jti cannot be revoked and are refused.
+func (a *Authenticator) AuthMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		authHeader := r.Header.Get("Authorization")
-		if authHeader == "" {
+		var principal *models.Principal
+		var err error
+		if key := apiKeyFromRequest(r); key != "" {
+			principal, err = a.apiKeys.AuthenticateAPIKey(r.Context(), key)
+		} else {
+			principal, err = a.tokenPrincipal(r)
+		}
+		if errors.Is(err, errInvalidToken) || errors.Is(err, models.ErrInvalidAPIKey) {
 			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
 
 			return
 		}
-		tokenString := authHeader[7:]
-		claims := &Claims{}
-		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-			return jwtKey, nil
-		})
-		if err != nil || !token.Valid {
-			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			log.Printf("Error authenticating request: %v", err)
 
 			return
 		}
 
-		ctx := context.WithValue(r.Context(), "username", claims.Username)
+		ctx := context.WithValue(r.Context(), "username", principal.Username)
+		ctx = context.WithValue(ctx, "role", principal.Role)
+		ctx = models.WithTenant(ctx, principal.TenantID)
+		ctx = context.WithValue(ctx, "session", principal.SessionID)
+		ctx = context.WithValue(ctx, "apiKey", principal.APIKeyID)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
+
+func apiKeyFromRequest(r *http.Request) string {
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return key
+	}
+	if key, ok := strings.CutPrefix(r.Header.Get("Authorization"), "ApiKey "); ok {
+		return strings.TrimSpace(key)
+	}
+	return ""
+}
+
+func (a *Authenticator) tokenPrincipal(r *http.Request) (*models.Principal, error) {
+	tokenString, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return nil, errInvalidToken
+	}
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, a.keys.Keyfunc)
+	// Tokens issued before tenancy carry no tenant and must be refreshed.
+	if err != nil || !token.Valid || claims.Id == "" || claims.TenantID == "" {
+		return nil, errInvalidToken
+	}
+	revoked, err := a.revocations.IsRevoked(r.Context(), claims.Id)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, errInvalidToken
+	}
+
+	// Older tokens only carry the username as the subject.
+	username := claims.Username
+	if username == "" {
+		username = claims.Subject
+	}
+	role := claims.Role
+	if role == "" {
+		role = models.RoleViewer
+	}
+	return &models.Principal{Username: username, Role: role, TenantID: claims.TenantID, SessionID: claims.SessionID}, nil
+}
+
+// UsernameFromContext returns the username AuthMiddleware stored for the
+// request, or an empty string outside authenticated routes.
+func UsernameFromContext(ctx context.Context) string {
+	username, _ := ctx.Value("username").(string)
+	return username
+}
+
+// RequireUsername is UsernameFromContext for per-user operations that must
+// not run anonymously.
+func RequireUsername(ctx context.Context) (string, error) {
+	username := UsernameFromContext(ctx)
+	if username == "" {
+		return "", ErrUnauthenticated
+	}
+	return username, nil
+}
+
+// RoleFromContext returns the role AuthMiddleware stored for the request.
+func RoleFromContext(ctx context.Context) string {
+	role, _ := ctx.Value("role").(string)
+	return role
+}
+
+// IsAdmin reports whether the request was made by an admin account.
+func IsAdmin(ctx context.Context) bool {
+	return models.RoleAtLeast(RoleFromContext(ctx), models.RoleAdmin)
+}
+
+// TenantFromContext returns the tenant AuthMiddleware stored for the
+// request, or an empty string outside authenticated routes.
+func TenantFromContext(ctx context.Context) string {
+	return models.TenantFromContext(ctx)
+}
+
+// RequireTenant is TenantFromContext for tenant-scoped data, which must
+// never be read without a tenant.
+func RequireTenant(ctx context.Context) (string, error) {
+	tenant := TenantFromContext(ctx)
+	if tenant == "" {
+		return "", models.ErrNoTenant
+	}
+	return tenant, nil
+}
+
+// APIKeyFromContext returns the ID of the API key the request was made with,
+// or an empty string for requests with an access token.
+func APIKeyFromContext(ctx context.Context) string {
+	apiKey, _ := ctx.Value("apiKey").(string)
+	return apiKey
+}
+
+// SessionFromContext returns the login session of the request's access token.
+func SessionFromContext(ctx context.Context) string {
+	session, _ := ctx.Value("session").(string)
+	return session
+}
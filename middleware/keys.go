@@ -0,0 +1,248 @@
+package middleware
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gloonch/CarZone/models"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const (
+	minRSABits      = 2048
+	minSecretLength = 32
+)
+
+// signingKey is one key of a KeySet. private is nil for keys that only
+// verify, such as the public half of a retired key.
+type signingKey struct {
+	id      string
+	method  jwt.SigningMethod
+	private interface{}
+	public  interface{}
+}
+
+// KeySet holds the keys tokens are signed and verified with. New tokens are
+// signed with one key and carry its ID in the kid header; every key in the
+// set verifies, so keys can be rotated without invalidating live tokens.
+type KeySet struct {
+	signing *signingKey
+	keys    map[string]*signingKey
+}
+
+// LoadKeySet reads every key in dir; the file name without its extension is
+// the key ID. PEM files (.pem) hold an RSA or P-256 EC key, private or public,
+// for RS256 or ES256. Files ending in .secret hold an HS256 secret, which is
+// never published. signingKeyID picks the key new tokens are signed with and
+// may be empty when dir holds a single private key.
+func LoadKeySet(dir string, signingKeyID string) (*KeySet, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	keySet := &KeySet{keys: map[string]*signingKey{}}
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".pem" && ext != ".secret") {
+			continue
+		}
+		id := strings.TrimSuffix(entry.Name(), ext)
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		var key *signingKey
+		if ext == ".secret" {
+			key, err = secretKey(id, data)
+		} else {
+			key, err = pemKey(id, data)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %w", entry.Name(), err)
+		}
+		if _, ok := keySet.keys[id]; ok {
+			return nil, fmt.Errorf("key ID %q is used by more than one file", id)
+		}
+		keySet.keys[id] = key
+	}
+
+	if signingKeyID == "" {
+		for _, key := range keySet.keys {
+			if key.private == nil {
+				continue
+			}
+			if keySet.signing != nil {
+				return nil, errors.New("several private keys found; choose the signing key")
+			}
+			keySet.signing = key
+		}
+		if keySet.signing == nil {
+			return nil, errors.New("no private key found to sign tokens with")
+		}
+		return keySet, nil
+	}
+
+	key, ok := keySet.keys[signingKeyID]
+	if !ok {
+		return nil, fmt.Errorf("signing key %q not found", signingKeyID)
+	}
+	if key.private == nil {
+		return nil, fmt.Errorf("signing key %q is a public key", signingKeyID)
+	}
+	keySet.signing = key
+	return keySet, nil
+}
+
+// GenerateKeySet returns a set with a fresh ES256 key. Tokens signed with it
+// stop verifying once the process exits, so it is only meant for
+// development.
+func GenerateKeySet() (*KeySet, error) {
+	private, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	key := &signingKey{
+		id:      "ephemeral",
+		method:  jwt.SigningMethodES256,
+		private: private,
+		public:  &private.PublicKey,
+	}
+	return &KeySet{signing: key, keys: map[string]*signingKey{key.id: key}}, nil
+}
+
+func secretKey(id string, data []byte) (*signingKey, error) {
+	secret := []byte(strings.TrimSpace(string(data)))
+	if len(secret) < minSecretLength {
+		return nil, fmt.Errorf("HS256 secrets must be at least %d bytes", minSecretLength)
+	}
+	return &signingKey{id: id, method: jwt.SigningMethodHS256, private: secret, public: secret}, nil
+}
+
+func pemKey(id string, data []byte) (*signingKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	var parsed interface{}
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		parsed, err = x509.ParseECPrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		parsed, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	key := &signingKey{id: id}
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.private, key.public = k, &k.PublicKey
+	case *ecdsa.PrivateKey:
+		key.private, key.public = k, &k.PublicKey
+	case *rsa.PublicKey, *ecdsa.PublicKey:
+		key.public = k
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", parsed)
+	}
+
+	switch k := key.public.(type) {
+	case *rsa.PublicKey:
+		if k.N.BitLen() < minRSABits {
+			return nil, fmt.Errorf("RSA keys must be at least %d bits", minRSABits)
+		}
+		key.method = jwt.SigningMethodRS256
+	case *ecdsa.PublicKey:
+		if k.Curve != elliptic.P256() {
+			return nil, errors.New("EC keys must use the P-256 curve")
+		}
+		key.method = jwt.SigningMethodES256
+	}
+	return key, nil
+}
+
+// Sign signs claims with the signing key and names it in the kid header.
+func (k *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(k.signing.method, claims)
+	token.Header["kid"] = k.signing.id
+	return token.SignedString(k.signing.private)
+}
+
+// Keyfunc finds the verification key named by a token's kid header. The
+// token's algorithm must be the key's, so an RSA public key can never be
+// used as an HMAC secret.
+func (k *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	id, _ := token.Header["kid"].(string)
+	key, ok := k.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("unknown key ID %q", id)
+	}
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("key %q does not sign with %s", id, token.Method.Alg())
+	}
+	return key.public, nil
+}
+
+// JWKS returns the public keys of the set. HS256 secrets are left out.
+func (k *KeySet) JWKS() models.JWKS {
+	ids := make([]string, 0, len(k.keys))
+	for id := range k.keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	jwks := models.JWKS{Keys: []models.JWK{}}
+	for _, id := range ids {
+		key := k.keys[id]
+		jwk := models.JWK{KeyID: id, Use: "sig", Algorithm: key.method.Alg()}
+		switch public := key.public.(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = encodeBase64URL(public.N.Bytes())
+			jwk.E = encodeBase64URL(big.NewInt(int64(public.E)).Bytes())
+		case *ecdsa.PublicKey:
+			ecdhKey, err := public.ECDH()
+			if err != nil {
+				continue
+			}
+			// The uncompressed point is 0x04 followed by X and Y.
+			point := ecdhKey.Bytes()
+			jwk.KeyType = "EC"
+			jwk.Curve = "P-256"
+			jwk.X = encodeBase64URL(point[1:33])
+			jwk.Y = encodeBase64URL(point[33:])
+		default:
+			continue
+		}
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+	return jwks
+}
+
+func encodeBase64URL(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
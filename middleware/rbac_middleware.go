@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gloonch/CarZone/models"
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var deniedCounter = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "http_access_denied_total",
+		Help: "Total number of requests refused for an insufficient role",
+	},
+	[]string{"path", "method", "required_role"},
+)
+
+func init() {
+	prometheus.MustRegister(deniedCounter)
+}
+
+// DenialRecorder stores refused requests so admins can review them.
+type DenialRecorder interface {
+	RecordDenial(ctx context.Context, denial models.AccessDenial) error
+}
+
+type Authorizer struct {
+	recorder DenialRecorder
+}
+
+func NewAuthorizer(recorder DenialRecorder) *Authorizer {
+	return &Authorizer{
+		recorder: recorder,
+	}
+}
+
+// Require returns middleware that only lets through requests whose role is
+// at least role. It must run after AuthMiddleware. Refused requests get 403
+// and are recorded.
+func (a *Authorizer) Require(role string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			callerRole := RoleFromContext(r.Context())
+			if models.RoleAtLeast(callerRole, role) {
+				next.ServeHTTP(w, r)
+
+				return
+			}
+
+			denial := models.AccessDenial{
+				Username:     UsernameFromContext(r.Context()),
+				Role:         callerRole,
+				RequiredRole: role,
+				Method:       r.Method,
+				Path:         r.URL.Path,
+				CreatedAt:    time.Now(),
+			}
+			template := r.URL.Path
+			if route := mux.CurrentRoute(r); route != nil {
+				if t, err := route.GetPathTemplate(); err == nil {
+					template = t
+				}
+			}
+			deniedCounter.WithLabelValues(template, r.Method, role).Inc()
+			log.Printf("Access denied: %s (%s) %s %s requires %s", denial.Username, callerRole, r.Method, r.URL.Path, role)
+			if err := a.recorder.RecordDenial(r.Context(), denial); err != nil {
+				log.Printf("Error recording access denial: %v", err)
+			}
+
+			http.Error(w, ErrForbidden.Error(), http.StatusForbidden)
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// API key scopes. A key acts with the role of its highest scope, but never
+// with more than its owner's current role.
+const (
+	ScopeRead  = "read"
+	ScopeWrite = "write"
+	ScopeAdmin = "admin"
+
+	maxAPIKeyNameLength = 100
+)
+
+var scopeRoles = map[string]string{
+	ScopeRead:  RoleViewer,
+	ScopeWrite: RoleEditor,
+	ScopeAdmin: RoleAdmin,
+}
+
+// APIKey is a credential for machine clients. Only a hash of the key is
+// stored; Prefix is kept so the key can be recognised in listings.
+type APIKey struct {
+	ID         uuid.UUID  `json:"id"`
+	Name       string     `json:"name"`
+	Owner      string     `json:"owner"`
+	Prefix     string     `json:"prefix"`
+	KeyHash    string     `json:"-"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"`
+	CreatedBy  string     `json:"createdBy"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+}
+
+type APIKeyRequest struct {
+	Name      string     `json:"name"`
+	Owner     string     `json:"owner"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
+
+// CreatedAPIKey is returned once when a key is created; Key is not stored
+// and cannot be shown again.
+type CreatedAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+// Principal is the identity a request is made with. SessionID is set for
+// access tokens, APIKeyID for API keys.
+type Principal struct {
+	Username  string
+	Role      string
+	TenantID  string
+	SessionID string
+	APIKeyID  string
+}
+
+var (
+	ErrInvalidAPIKey  = errors.New("API key is invalid, expired or revoked")
+	ErrAPIKeyNotFound = errors.New("API key does not exist")
+	// ErrInvalidAPIKeyRequest is wrapped by the errors of a request the key
+	// cannot be issued for.
+	ErrInvalidAPIKeyRequest = errors.New("invalid API key request")
+)
+
+func (keyReq *APIKeyRequest) Normalize() {
+	keyReq.Name = strings.TrimSpace(keyReq.Name)
+	keyReq.Owner = strings.ToLower(strings.TrimSpace(keyReq.Owner))
+
+	seen := make(map[string]bool, len(keyReq.Scopes))
+	scopes := []string{}
+	for _, scope := range keyReq.Scopes {
+		scope = strings.ToLower(strings.TrimSpace(scope))
+		if scope == "" || seen[scope] {
+			continue
+		}
+		seen[scope] = true
+		scopes = append(scopes, scope)
+	}
+	sort.Strings(scopes)
+	keyReq.Scopes = scopes
+}
+
+func ValidateAPIKeyRequest(keyReq APIKeyRequest, now time.Time) error {
+	if keyReq.Name == "" {
+		return errors.New("name is required")
+	}
+	if len(keyReq.Name) > maxAPIKeyNameLength {
+		return fmt.Errorf("name must be at most %d characters", maxAPIKeyNameLength)
+	}
+	if keyReq.Owner == "" {
+		return errors.New("owner is required")
+	}
+	if len(keyReq.Scopes) == 0 {
+		return errors.New("at least one scope is required")
+	}
+	for _, scope := range keyReq.Scopes {
+		if _, ok := scopeRoles[scope]; !ok {
+			return fmt.Errorf("unknown scope %q; use %s, %s or %s", scope, ScopeRead, ScopeWrite, ScopeAdmin)
+		}
+	}
+	if keyReq.ExpiresAt != nil && !keyReq.ExpiresAt.After(now) {
+		return errors.New("expiresAt must be in the future")
+	}
+	return nil
+}
+
+// ScopeRole returns the role granted by the highest of the scopes.
+func ScopeRole(scopes []string) string {
+	role := RoleViewer
+	for _, scope := range scopes {
+		if scopeRole, ok := scopeRoles[scope]; ok && RoleAtLeast(scopeRole, role) {
+			role = scopeRole
+		}
+	}
+	return role
+}
@@ -0,0 +1,104 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	maxAttributes      = 50
+	maxAttributeLength = 255
+	maxTags            = 30
+)
+
+var (
+	attributeKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,63}$`)
+	tagPattern          = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,49}$`)
+)
+
+// Attributes are free-form dealer features of a car such as colour or
+// towing package, stored in a JSONB column. Values are strings so that
+// query-string filters compare exactly.
+type Attributes map[string]string
+
+// Tag is a label shared by many cars, with the number of cars carrying it.
+type Tag struct {
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+
+func (a *Attributes) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		*a = nil
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into Attributes", src)
+	}
+	return json.Unmarshal(data, a)
+}
+
+func (a Attributes) Value() (driver.Value, error) {
+	if a == nil {
+		return "{}", nil
+	}
+	data, err := json.Marshal(a)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// NormalizeTags lower-cases, trims and de-duplicates tags, returning them
+// sorted.
+func NormalizeTags(tags []string) []string {
+	seen := make(map[string]bool, len(tags))
+	normalized := []string{}
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	sort.Strings(normalized)
+	return normalized
+}
+
+func ValidateAttributes(attributes Attributes) error {
+	if len(attributes) > maxAttributes {
+		return errors.New(fmt.Sprintf("a car can have at most %d attributes", maxAttributes))
+	}
+	for key, value := range attributes {
+		if !attributeKeyPattern.MatchString(key) {
+			return errors.New(fmt.Sprintf("attribute %q must be lower-case letters, digits and underscores", key))
+		}
+		if value == "" || len(value) > maxAttributeLength {
+			return errors.New(fmt.Sprintf("attribute %q must have a value of at most %d characters", key, maxAttributeLength))
+		}
+	}
+	return nil
+}
+
+func ValidateTags(tags []string) error {
+	if len(tags) > maxTags {
+		return errors.New(fmt.Sprintf("a car can have at most %d tags", maxTags))
+	}
+	for _, tag := range tags {
+		if !tagPattern.MatchString(tag) {
+			return errors.New(fmt.Sprintf("tag %q must be lower-case letters, digits and dashes", tag))
+		}
+	}
+	return nil
+}
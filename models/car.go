@@ -4,30 +4,65 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// Car is a listing. CreatedBy is its owner, the user who listed it, and
+// Location the dealership location it is listed at.
 type Car struct {
-	ID        uuid.UUID `json:"id"`
-	Name      string    `json:"name"`
-	Year      string    `json:"year"`
-	Brand     string    `json:"brand"`
-	FuelType  string    `json:"fuelType"`
-	Engine    Engine    `json:"engine"`
-	Price     float64   `json:"price"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID             uuid.UUID       `json:"id"`
+	Name           string          `json:"name"`
+	Year           string          `json:"year"`
+	Brand          string          `json:"brand"`
+	FuelType       string          `json:"fuelType"`
+	Engine         Engine          `json:"engine"`
+	Price          Money           `json:"price"`
+	Mileage        int64           `json:"mileage"`
+	Condition      string          `json:"condition"`
+	PreviousOwners int             `json:"previousOwners"`
+	DamageNotes    string          `json:"damageNotes"`
+	Attributes     Attributes      `json:"attributes"`
+	Tags           []string        `json:"tags"`
+	ConvertedPrice *ConvertedPrice `json:"convertedPrice,omitempty"`
+	Images         []CarMedia      `json:"images,omitempty"`
+	IsFavorite     bool            `json:"isFavorite"`
+	Rating         Rating          `json:"rating"`
+	Location       string          `json:"location"`
+	CreatedBy      string          `json:"createdBy"`
+	UpdatedBy      string          `json:"updatedBy"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
 }
 
 type CarRequest struct {
-	Name     string  `json:"name"`
-	Year     string  `json:"year"`
-	Brand    string  `json:"brand"`
-	FuelType string  `json:"fuelType"`
-	Engine   Engine  `json:"engine"`
-	Price    float64 `json:"price"`
+	Name             string            `json:"name"`
+	Year             string            `json:"year"`
+	Brand            string            `json:"brand"`
+	FuelType         string            `json:"fuelType"`
+	Engine           Engine            `json:"engine"`
+	Price            Money             `json:"price"`
+	Mileage          int64             `json:"mileage"`
+	Condition        string            `json:"condition"`
+	PreviousOwners   int               `json:"previousOwners"`
+	DamageNotes      string            `json:"damageNotes"`
+	Attributes       Attributes        `json:"attributes"`
+	Tags             []string          `json:"tags"`
+	OdometerOverride *OdometerOverride `json:"odometerOverride,omitempty"`
+	Location         string            `json:"location"`
+}
+
+// Normalize fills in the condition of cars listed without one as new stock
+// and puts tags into their canonical form.
+func (carRequest *CarRequest) Normalize() {
+	if carRequest.Condition == "" {
+		carRequest.Condition = ConditionNew
+	}
+	carRequest.DamageNotes = strings.TrimSpace(carRequest.DamageNotes)
+	carRequest.Location = strings.TrimSpace(carRequest.Location)
+	carRequest.Tags = NormalizeTags(carRequest.Tags)
 }
 
 func ValidateCarRequest(carRequest CarRequest) error {
@@ -49,6 +84,23 @@ func ValidateCarRequest(carRequest CarRequest) error {
 	if err := ValidatePrice(carRequest.Price); err != nil {
 		return err
 	}
+	if err := ValidateUsedCarDetails(carRequest); err != nil {
+		return err
+	}
+	if err := ValidateAttributes(carRequest.Attributes); err != nil {
+		return err
+	}
+	if err := ValidateTags(carRequest.Tags); err != nil {
+		return err
+	}
+	if err := ValidateLocation(carRequest.Location); err != nil {
+		return err
+	}
+	if carRequest.OdometerOverride != nil {
+		if err := ValidateOdometerOverride(*carRequest.OdometerOverride); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -75,6 +127,16 @@ func ValidateYear(year string) error {
 	return nil
 }
 
+// maxLocationLength matches the location columns of car and users.
+const maxLocationLength = 100
+
+func ValidateLocation(location string) error {
+	if len(location) > maxLocationLength {
+		return fmt.Errorf("location must be at most %d characters", maxLocationLength)
+	}
+	return nil
+}
+
 func ValidateBrand(brand string) error {
 	if brand == "" {
 		return errors.New("brand is required")
@@ -96,21 +158,32 @@ func ValidateEngine(engine Engine) error {
 	if engine.EngineID == uuid.Nil {
 		return errors.New("EngineID is required")
 	}
-	if engine.Displacement <= 0 {
-		return errors.New("displacement must be greater than zero")
+	if engine.EngineType == "" {
+		engine.EngineType = EngineTypeICE
 	}
-	if engine.NoOfCylinders <= 0 {
-		return errors.New("NoOfCylinders must be greater than zero")
-	}
-	if engine.CarRange <= 0 {
-		return errors.New("CarRange must be greater than zero")
-	}
-	return nil
+	return validateEngineSpecs(engine)
 }
 
-func ValidatePrice(price float64) error {
-	if price <= 0 {
+// ErrCarNotFound is returned when a car does not exist in the caller's
+// tenant.
+var ErrCarNotFound = errors.New("Car not found")
+
+// maxPrice is the first amount that no longer fits the NUMERIC(19, 2) price
+// column.
+var maxPrice = MustParseDecimal("100000000000000000")
+
+func ValidatePrice(price Money) error {
+	if price.Amount.Sign() <= 0 {
 		return errors.New("Price must be greater than zero")
 	}
+	if price.Amount.Places() > 2 {
+		return errors.New("Price must not have more than two decimal places")
+	}
+	if price.Amount.Cmp(maxPrice) >= 0 {
+		return errors.New(fmt.Sprintf("Price must be less than %s", maxPrice.StringFixed(0)))
+	}
+	if err := ValidateCurrency(price.Currency); err != nil {
+		return err
+	}
 	return nil
 }
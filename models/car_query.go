@@ -0,0 +1,56 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	CarSortRating  = "rating"
+	CarSortReviews = "reviews"
+)
+
+var carSorts = []string{CarSortRating, CarSortReviews}
+
+// CarQuery holds the options of a car listing request. Nil filters are not
+// applied.
+type CarQuery struct {
+	Brand             string
+	IsEngine          bool
+	Currency          string
+	Sort              string
+	MinMileage        *int64
+	MaxMileage        *int64
+	Condition         string
+	MaxPreviousOwners *int
+	Damaged           *bool
+	Attributes        Attributes
+	Tags              []string
+}
+
+func ValidateCarQuery(query CarQuery) error {
+	if query.Sort != "" && !contains(carSorts, query.Sort) {
+		return errors.New(fmt.Sprintf("sort must be one of %v", carSorts))
+	}
+	if (query.MinMileage != nil && *query.MinMileage < 0) || (query.MaxMileage != nil && *query.MaxMileage < 0) {
+		return errors.New("mileage filters must not be negative")
+	}
+	if query.MinMileage != nil && query.MaxMileage != nil && *query.MinMileage > *query.MaxMileage {
+		return errors.New("minMileage must not be greater than maxMileage")
+	}
+	if query.Condition != "" {
+		if err := ValidateCondition(query.Condition); err != nil {
+			return err
+		}
+	}
+	if query.MaxPreviousOwners != nil && *query.MaxPreviousOwners < 0 {
+		return errors.New("maxPreviousOwners must not be negative")
+	}
+	if err := ValidateAttributes(query.Attributes); err != nil {
+		return err
+	}
+	if err := ValidateTags(query.Tags); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+const MaxComparedCars = 5
+
+// CarComparison lines cars up attribute by attribute. Every entry in
+// Attributes has one value per car, in the same order as Cars.
+type CarComparison struct {
+	Cars       []Car                 `json:"cars"`
+	Attributes []ComparisonAttribute `json:"attributes"`
+}
+
+type ComparisonAttribute struct {
+	Name    string        `json:"name"`
+	Values  []interface{} `json:"values"`
+	Differs bool          `json:"differs"`
+	Best    []uuid.UUID   `json:"best,omitempty"`
+	Worst   []uuid.UUID   `json:"worst,omitempty"`
+}
+
+func ValidateComparisonIDs(ids []string) error {
+	if len(ids) < 2 {
+		return errors.New("at least two car ids are required")
+	}
+	if len(ids) > MaxComparedCars {
+		return errors.New(fmt.Sprintf("at most %d cars can be compared", MaxComparedCars))
+	}
+	seen := make(map[uuid.UUID]bool, len(ids))
+	for _, id := range ids {
+		parsed, err := uuid.Parse(id)
+		if err != nil {
+			return errors.New(fmt.Sprintf("invalid car id %q", id))
+		}
+		// Upper case, braces and the urn:uuid: prefix all name the same car.
+		if seen[parsed] {
+			return errors.New(fmt.Sprintf("car id %q is listed twice", id))
+		}
+		seen[parsed] = true
+	}
+	return nil
+}
+
+// CanonicalCarIDs rewrites validated car ids into the lower-case form the
+// store returns, so results can be matched back to the request.
+func CanonicalCarIDs(ids []string) []string {
+	canonical := make([]string, len(ids))
+	for i, id := range ids {
+		canonical[i] = uuid.MustParse(id).String()
+	}
+	return canonical
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidateComparisonIDsTreatsSpellingsOfAnIDAsTheSameCar(t *testing.T) {
+	id := "5b0f9c4e-2a6d-4f3e-9d7a-1c2b3a4d5e6f"
+	for _, other := range []string{
+		"5B0F9C4E-2A6D-4F3E-9D7A-1C2B3A4D5E6F",
+		"{5b0f9c4e-2a6d-4f3e-9d7a-1c2b3a4d5e6f}",
+		"urn:uuid:5b0f9c4e-2a6d-4f3e-9d7a-1c2b3a4d5e6f",
+		"5b0f9c4e2a6d4f3e9d7a1c2b3a4d5e6f",
+	} {
+		if err := ValidateComparisonIDs([]string{id, other}); err == nil {
+			t.Errorf("%q and %q were accepted as two cars", id, other)
+		}
+	}
+
+	ids := []string{"5B0F9C4E-2A6D-4F3E-9D7A-1C2B3A4D5E6F", "{0d8e7f6a-5b4c-4d3e-8f2a-1b0c9d8e7f6a}"}
+	if err := ValidateComparisonIDs(ids); err != nil {
+		t.Fatalf("ValidateComparisonIDs: %v", err)
+	}
+	want := []string{"5b0f9c4e-2a6d-4f3e-9d7a-1c2b3a4d5e6f", "0d8e7f6a-5b4c-4d3e-8f2a-1b0c9d8e7f6a"}
+	if got := CanonicalCarIDs(ids); !reflect.DeepEqual(got, want) {
+		t.Fatalf("CanonicalCarIDs: got %v, want %v", got, want)
+	}
+}
@@ -2,33 +2,184 @@ package models
 
 import (
 	"errors"
+	"fmt"
+	"math"
 
 	"github.com/google/uuid"
 )
 
+const (
+	EngineTypeICE      = "ICE"
+	EngineTypeElectric = "Electric"
+	EngineTypeHybrid   = "Hybrid"
+
+	kwPerHP = 0.745699872
+
+	// Upper bounds of the NUMERIC(7, 2) and NUMERIC(6, 2) engine columns.
+	maxPowerKW         = 100000
+	maxTorqueNm        = 100000
+	maxBatteryCapacity = 10000
+	maxChargingPowerKW = 10000
+)
+
+var (
+	validEngineTypes   = []string{EngineTypeICE, EngineTypeElectric, EngineTypeHybrid}
+	validAspirations   = []string{"NaturallyAspirated", "Turbocharged", "Supercharged", "TwinCharged"}
+	validTransmissions = []string{"Manual", "Automatic", "DCT", "CVT", "SingleSpeed"}
+)
+
 type Engine struct {
-	EngineID      uuid.UUID `json:"engine_id"`
-	Displacement  int64     `json:"displacement"`
-	NoOfCylinders int64     `json:"no_of_cylinder"`
-	CarRange      int64     `json:"car_range"`
+	EngineID           uuid.UUID `json:"engine_id"`
+	EngineType         string    `json:"engine_type"`
+	Displacement       int64     `json:"displacement"`
+	NoOfCylinders      int64     `json:"no_of_cylinder"`
+	CarRange           int64     `json:"car_range"`
+	PowerKW            *float64  `json:"power_kw,omitempty"`
+	PowerHP            *float64  `json:"power_hp,omitempty"`
+	TorqueNm           *float64  `json:"torque_nm,omitempty"`
+	Aspiration         *string   `json:"aspiration,omitempty"`
+	Transmission       *string   `json:"transmission,omitempty"`
+	BatteryCapacityKWh *float64  `json:"battery_capacity_kwh,omitempty"`
+	ChargingPowerKW    *float64  `json:"charging_power_kw,omitempty"`
 }
 
 type EngineRequest struct {
-	Displacement  int64 `json:"displacement"`
-	NoOfCylinders int64 `json:"noOfCylinders"`
-	CarRange      int64 `json:"carRange"`
+	EngineType         string   `json:"engineType"`
+	Displacement       int64    `json:"displacement"`
+	NoOfCylinders      int64    `json:"noOfCylinders"`
+	CarRange           int64    `json:"carRange"`
+	PowerKW            *float64 `json:"powerKw"`
+	PowerHP            *float64 `json:"powerHp"`
+	TorqueNm           *float64 `json:"torqueNm"`
+	Aspiration         *string  `json:"aspiration"`
+	Transmission       *string  `json:"transmission"`
+	BatteryCapacityKWh *float64 `json:"batteryCapacityKwh"`
+	ChargingPowerKW    *float64 `json:"chargingPowerKw"`
+}
+
+// Normalize fills in defaults and converts power given in hp to kW, which is
+// the unit that is stored.
+func (engineReq *EngineRequest) Normalize() {
+	if engineReq.EngineType == "" {
+		engineReq.EngineType = EngineTypeICE
+	}
+	if engineReq.PowerKW == nil && engineReq.PowerHP != nil {
+		kw := roundTo(*engineReq.PowerHP*kwPerHP, 2)
+		engineReq.PowerKW = &kw
+		engineReq.PowerHP = nil
+	}
+}
+
+// Engine builds the engine described by the request, deriving hp from kW.
+func (engineReq EngineRequest) Engine(id uuid.UUID) Engine {
+	engine := Engine{
+		EngineID:           id,
+		EngineType:         engineReq.EngineType,
+		Displacement:       engineReq.Displacement,
+		NoOfCylinders:      engineReq.NoOfCylinders,
+		CarRange:           engineReq.CarRange,
+		PowerKW:            engineReq.PowerKW,
+		TorqueNm:           engineReq.TorqueNm,
+		Aspiration:         engineReq.Aspiration,
+		Transmission:       engineReq.Transmission,
+		BatteryCapacityKWh: engineReq.BatteryCapacityKWh,
+		ChargingPowerKW:    engineReq.ChargingPowerKW,
+	}
+	engine.DerivePowerHP()
+	return engine
+}
+
+func (engine *Engine) DerivePowerHP() {
+	engine.PowerHP = nil
+	if engine.PowerKW != nil {
+		hp := roundTo(*engine.PowerKW/kwPerHP, 1)
+		engine.PowerHP = &hp
+	}
 }
 
 func ValidateEngineRequest(engine EngineRequest) error {
-	if err := validateDisplacement(engine.Displacement); err != nil {
+	if engine.PowerKW != nil && engine.PowerHP != nil {
+		return errors.New("specify either powerKw or powerHp, not both")
+	}
+	engineType := engine.EngineType
+	if engineType == "" {
+		engineType = EngineTypeICE
+	}
+	return validateEngineSpecs(Engine{
+		EngineType:         engineType,
+		Displacement:       engine.Displacement,
+		NoOfCylinders:      engine.NoOfCylinders,
+		CarRange:           engine.CarRange,
+		PowerKW:            engine.PowerKW,
+		PowerHP:            engine.PowerHP,
+		TorqueNm:           engine.TorqueNm,
+		Aspiration:         engine.Aspiration,
+		Transmission:       engine.Transmission,
+		BatteryCapacityKWh: engine.BatteryCapacityKWh,
+		ChargingPowerKW:    engine.ChargingPowerKW,
+	})
+}
+
+// validateEngineSpecs checks that the specs fit the engine type: electric
+// motors have no displacement, cylinders or aspiration but need a battery,
+// while combustion engines must not report battery or charging figures.
+func validateEngineSpecs(engine Engine) error {
+	if !contains(validEngineTypes, engine.EngineType) {
+		return errors.New(fmt.Sprintf("engineType must be one of %v", validEngineTypes))
+	}
+
+	if engine.EngineType == EngineTypeElectric {
+		if engine.Displacement != 0 || engine.NoOfCylinders != 0 {
+			return errors.New("electric engines must not have displacement or cylinders")
+		}
+		if engine.Aspiration != nil {
+			return errors.New("electric engines must not have aspiration")
+		}
+		if engine.BatteryCapacityKWh == nil {
+			return errors.New("batteryCapacityKwh is required for electric engines")
+		}
+	} else {
+		if err := validateDisplacement(engine.Displacement); err != nil {
+			return err
+		}
+		if err := validateNoOfCylinders(engine.NoOfCylinders); err != nil {
+			return err
+		}
+		if engine.Aspiration != nil && !contains(validAspirations, *engine.Aspiration) {
+			return errors.New(fmt.Sprintf("aspiration must be one of %v", validAspirations))
+		}
+	}
+	if engine.EngineType == EngineTypeICE {
+		if engine.BatteryCapacityKWh != nil || engine.ChargingPowerKW != nil {
+			return errors.New("combustion engines must not have battery or charging specs")
+		}
+	}
+
+	if err := validateCarRange(engine.CarRange); err != nil {
 		return err
 	}
-	if err := validateNoOfCylinders(engine.NoOfCylinders); err != nil {
+	if err := validateRange("power", engine.PowerKW, maxPowerKW); err != nil {
 		return err
 	}
-	if err := validateCarRange(engine.CarRange); err != nil {
+	if engine.PowerHP != nil {
+		// Power given in hp is stored in kW.
+		kw := *engine.PowerHP * kwPerHP
+		if err := validateRange("power", &kw, maxPowerKW); err != nil {
+			return err
+		}
+	}
+	if err := validateRange("torqueNm", engine.TorqueNm, maxTorqueNm); err != nil {
 		return err
 	}
+	if err := validateRange("batteryCapacityKwh", engine.BatteryCapacityKWh, maxBatteryCapacity); err != nil {
+		return err
+	}
+	if err := validateRange("chargingPowerKw", engine.ChargingPowerKW, maxChargingPowerKW); err != nil {
+		return err
+	}
+	if engine.Transmission != nil && !contains(validTransmissions, *engine.Transmission) {
+		return errors.New(fmt.Sprintf("transmission must be one of %v", validTransmissions))
+	}
 	return nil
 }
 
@@ -52,3 +203,32 @@ func validateCarRange(carRange int64) error {
 	}
 	return nil
 }
+
+// validateRange checks that a figure is greater than zero and, once rounded
+// to the two decimals that are stored, below the column's limit.
+func validateRange(name string, value *float64, limit float64) error {
+	if value == nil {
+		return nil
+	}
+	if !(*value > 0) {
+		return errors.New(name + " must be greater than zero")
+	}
+	if roundTo(*value, 2) >= limit {
+		return errors.New(fmt.Sprintf("%s must be less than %v", name, limit))
+	}
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func roundTo(value float64, places int) float64 {
+	scale := math.Pow(10, float64(places))
+	return math.Round(value*scale) / scale
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"math"
+	"testing"
+)
+
+func TestValidateEngineRequestRejectsFiguresTheColumnsCannotHold(t *testing.T) {
+	figure := func(value float64) *float64 { return &value }
+	tests := []struct {
+		name  string
+		req   EngineRequest
+		valid bool
+	}{
+		{"largest power", EngineRequest{PowerKW: figure(99999.99)}, true},
+		{"power too large", EngineRequest{PowerKW: figure(100000)}, false},
+		{"power rounding up to the limit", EngineRequest{PowerKW: figure(99999.996)}, false},
+		{"power in hp too large", EngineRequest{PowerHP: figure(150000)}, false},
+		{"torque too large", EngineRequest{TorqueNm: figure(1e6)}, false},
+		{"power not a number", EngineRequest{PowerKW: figure(math.NaN())}, false},
+		{"battery too large", EngineRequest{EngineType: EngineTypeElectric, BatteryCapacityKWh: figure(10000)}, false},
+		{"charging power too large", EngineRequest{EngineType: EngineTypeHybrid, Displacement: 1600, NoOfCylinders: 4, BatteryCapacityKWh: figure(20), ChargingPowerKW: figure(12000)}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.req.CarRange = 500
+			if tt.req.EngineType == "" {
+				tt.req.Displacement, tt.req.NoOfCylinders = 2000, 4
+			}
+			err := ValidateEngineRequest(tt.req)
+			if tt.valid && err != nil {
+				t.Fatalf("ValidateEngineRequest: %v", err)
+			}
+			if !tt.valid && err == nil {
+				t.Fatal("ValidateEngineRequest accepted the engine")
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const DateLayout = "2006-01-02"
+
+// ErrNoExchangeRate is returned when a price is asked for in a currency no
+// rate has been recorded for.
+var ErrNoExchangeRate = errors.New("no rate for currency")
+
+type ExchangeRate struct {
+	ID            uuid.UUID `json:"id"`
+	BaseCurrency  string    `json:"baseCurrency"`
+	QuoteCurrency string    `json:"quoteCurrency"`
+	Rate          Decimal   `json:"rate"`
+	RateDate      string    `json:"rateDate"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type ExchangeRateRequest struct {
+	BaseCurrency  string  `json:"baseCurrency"`
+	QuoteCurrency string  `json:"quoteCurrency"`
+	Rate          Decimal `json:"rate"`
+	RateDate      string  `json:"rateDate"`
+}
+
+// ConvertedPrice is a car price expressed in a currency other than the one
+// it is listed in, together with the rate that produced it.
+type ConvertedPrice struct {
+	Price    Money   `json:"price"`
+	Rate     Decimal `json:"rate"`
+	RateDate string  `json:"rateDate"`
+}
+
+func ValidateExchangeRateRequest(rateReq ExchangeRateRequest) error {
+	if err := ValidateCurrency(rateReq.BaseCurrency); err != nil {
+		return err
+	}
+	if err := ValidateCurrency(rateReq.QuoteCurrency); err != nil {
+		return err
+	}
+	if rateReq.BaseCurrency == rateReq.QuoteCurrency {
+		return errors.New("baseCurrency and quoteCurrency must differ")
+	}
+	if rateReq.Rate.Sign() <= 0 {
+		return errors.New("rate must be greater than zero")
+	}
+	if rateReq.Rate.Places() > 10 {
+		return errors.New("rate must not have more than ten decimal places")
+	}
+	if rateReq.RateDate != "" {
+		if _, err := time.Parse(DateLayout, rateReq.RateDate); err != nil {
+			return errors.New("rateDate must be formatted as YYYY-MM-DD")
+		}
+	}
+	return nil
+}
+
+// Convert applies rate to price, rounding the result to whole cents.
+func (rate ExchangeRate) Convert(price Money) ConvertedPrice {
+	return ConvertedPrice{
+		Price:    NewMoney(price.Amount.Mul(rate.Rate).Round(2), rate.QuoteCurrency),
+		Rate:     rate.Rate,
+		RateDate: rate.RateDate,
+	}
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrFavoriteNotFound is returned when the user has not starred the car.
+var ErrFavoriteNotFound = errors.New("favorite does not exist")
+
+type Favorite struct {
+	Car       Car       `json:"car"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type FavoriteRequest struct {
+	CarID uuid.UUID `json:"carId"`
+}
+
+func ValidateFavoriteRequest(favoriteReq FavoriteRequest) error {
+	if favoriteReq.CarID == uuid.Nil {
+		return errors.New("carId is required")
+	}
+	return nil
+}
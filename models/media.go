@@ -0,0 +1,85 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	MediaKindImage    = "image"
+	MediaKindDocument = "document"
+
+	MaxMediaSize = 10 << 20
+
+	// MaxImagePixels bounds the width × height of an uploaded image, which
+	// is decoded in full to make its thumbnail.
+	MaxImagePixels = 40_000_000
+)
+
+var (
+	ErrMediaNotFound = errors.New("media does not exist")
+	// ErrInvalidMedia wraps every reason an upload is refused, as opposed to
+	// a failure to store it.
+	ErrInvalidMedia = errors.New("invalid media file")
+)
+
+var mediaKinds = map[string]string{
+	"image/jpeg":      MediaKindImage,
+	"image/png":       MediaKindImage,
+	"image/gif":       MediaKindImage,
+	"application/pdf": MediaKindDocument,
+}
+
+type CarMedia struct {
+	ID           uuid.UUID `json:"id"`
+	CarID        uuid.UUID `json:"carId"`
+	Kind         string    `json:"kind"`
+	FileName     string    `json:"fileName"`
+	ContentType  string    `json:"contentType"`
+	Size         int64     `json:"size"`
+	URL          string    `json:"url"`
+	ThumbnailURL string    `json:"thumbnailUrl,omitempty"`
+	BlobKey      string    `json:"-"`
+	ThumbnailKey string    `json:"-"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// SetURLs fills in the API paths clients use to download the media.
+func (m *CarMedia) SetURLs() {
+	m.URL = fmt.Sprintf("/cars/%s/images/%s", m.CarID, m.ID)
+	m.ThumbnailURL = ""
+	if m.ThumbnailKey != "" {
+		m.ThumbnailURL = m.URL + "/thumbnail"
+	}
+}
+
+// MediaKind returns the kind of attachment for an accepted content type.
+func MediaKind(contentType string) (string, error) {
+	kind, ok := mediaKinds[contentType]
+	if !ok {
+		return "", fmt.Errorf("content type %q is not allowed", contentType)
+	}
+	return kind, nil
+}
+
+func ValidateMediaUpload(fileName string, contentType string, size int64) error {
+	if fileName == "" {
+		return errors.New("fileName is required")
+	}
+	if len(fileName) > 255 {
+		return errors.New("fileName must be at most 255 characters")
+	}
+	if size <= 0 {
+		return errors.New("file is empty")
+	}
+	if size > MaxMediaSize {
+		return fmt.Errorf("file must be at most %d bytes", MaxMediaSize)
+	}
+	if _, err := MediaKind(contentType); err != nil {
+		return err
+	}
+	return nil
+}
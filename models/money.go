@@ -0,0 +1,212 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+)
+
+// Decimal is an exact decimal number. It is backed by a rational so values
+// read from NUMERIC columns or JSON round-trip without float rounding.
+type Decimal struct {
+	rat *big.Rat
+}
+
+type Money struct {
+	Amount   Decimal `json:"amount"`
+	Currency string  `json:"currency"`
+}
+
+const maxDecimalPlaces = 18
+
+var currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+func NewDecimal(value int64) Decimal {
+	return Decimal{rat: new(big.Rat).SetInt64(value)}
+}
+
+func ParseDecimal(value string) (Decimal, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return Decimal{}, errors.New("decimal value is empty")
+	}
+	if strings.ContainsAny(value, "/eE") {
+		return Decimal{}, fmt.Errorf("invalid decimal value %q", value)
+	}
+	r, ok := new(big.Rat).SetString(value)
+	if !ok {
+		return Decimal{}, fmt.Errorf("invalid decimal value %q", value)
+	}
+	return Decimal{rat: r}, nil
+}
+
+func MustParseDecimal(value string) Decimal {
+	d, err := ParseDecimal(value)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func (d Decimal) value() *big.Rat {
+	if d.rat == nil {
+		return new(big.Rat)
+	}
+	return d.rat
+}
+
+func (d Decimal) Add(other Decimal) Decimal {
+	return Decimal{rat: new(big.Rat).Add(d.value(), other.value())}
+}
+
+func (d Decimal) Sub(other Decimal) Decimal {
+	return Decimal{rat: new(big.Rat).Sub(d.value(), other.value())}
+}
+
+func (d Decimal) Mul(other Decimal) Decimal {
+	return Decimal{rat: new(big.Rat).Mul(d.value(), other.value())}
+}
+
+// Quo returns d divided by other. The result is exact; callers round it.
+// Dividing by zero panics.
+func (d Decimal) Quo(other Decimal) Decimal {
+	return Decimal{rat: new(big.Rat).Quo(d.value(), other.value())}
+}
+
+func (d Decimal) Cmp(other Decimal) int {
+	return d.value().Cmp(other.value())
+}
+
+func (d Decimal) Sign() int {
+	return d.value().Sign()
+}
+
+func (d Decimal) IsZero() bool {
+	return d.Sign() == 0
+}
+
+func (d Decimal) Float64() float64 {
+	f, _ := d.value().Float64()
+	return f
+}
+
+// Places returns the number of fractional digits needed to represent d
+// exactly, capped at maxDecimalPlaces.
+func (d Decimal) Places() int {
+	r := new(big.Rat).Set(d.value())
+	ten := big.NewRat(10, 1)
+	for places := 0; places < maxDecimalPlaces; places++ {
+		if r.IsInt() {
+			return places
+		}
+		r.Mul(r, ten)
+	}
+	return maxDecimalPlaces
+}
+
+// Round rounds d to the given number of fractional digits, with halves
+// rounded away from zero.
+func (d Decimal) Round(places int) Decimal {
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(places)), nil)
+	scaled := new(big.Rat).Mul(d.value(), new(big.Rat).SetInt(scale))
+
+	num := new(big.Int).Abs(scaled.Num())
+	quo, rem := new(big.Int).QuoRem(num, scaled.Denom(), new(big.Int))
+	if new(big.Int).Mul(rem, big.NewInt(2)).Cmp(scaled.Denom()) >= 0 {
+		quo.Add(quo, big.NewInt(1))
+	}
+	if scaled.Sign() < 0 {
+		quo.Neg(quo)
+	}
+	return Decimal{rat: new(big.Rat).SetFrac(quo, scale)}
+}
+
+func (d Decimal) StringFixed(places int) string {
+	return d.value().FloatString(places)
+}
+
+func (d Decimal) String() string {
+	places := d.Places()
+	if places < 2 {
+		places = 2
+	}
+	return d.StringFixed(places)
+}
+
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON accepts both the string form written by MarshalJSON and a
+// bare JSON number, which is parsed from its literal text without passing
+// through float64.
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	raw := strings.TrimSpace(string(data))
+	if raw == "null" {
+		*d = Decimal{}
+		return nil
+	}
+	if strings.HasPrefix(raw, `"`) {
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return err
+		}
+	}
+	parsed, err := ParseDecimal(raw)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+func (d *Decimal) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*d = Decimal{}
+		return nil
+	case []byte:
+		parsed, err := ParseDecimal(string(v))
+		if err != nil {
+			return err
+		}
+		*d = parsed
+		return nil
+	case string:
+		parsed, err := ParseDecimal(v)
+		if err != nil {
+			return err
+		}
+		*d = parsed
+		return nil
+	case int64:
+		*d = NewDecimal(v)
+		return nil
+	default:
+		return fmt.Errorf("cannot scan %T into Decimal", src)
+	}
+}
+
+func (d Decimal) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+func NewMoney(amount Decimal, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+func (m Money) String() string {
+	return m.Amount.StringFixed(2) + " " + m.Currency
+}
+
+var ErrInvalidCurrency = errors.New("currency must be a three-letter ISO 4217 code")
+
+func ValidateCurrency(currency string) error {
+	if !currencyPattern.MatchString(currency) {
+		return ErrInvalidCurrency
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ConditionNew       = "New"
+	ConditionExcellent = "Excellent"
+	ConditionGood      = "Good"
+	ConditionFair      = "Fair"
+	ConditionPoor      = "Poor"
+
+	// maxMileage is a generous upper bound in km that still catches typos.
+	maxMileage        = 2000000
+	maxPreviousOwners = 99
+)
+
+var validConditions = []string{ConditionNew, ConditionExcellent, ConditionGood, ConditionFair, ConditionPoor}
+
+var ErrOdometerDecrease = errors.New("mileage must not be lower than the recorded odometer reading without an odometerOverride")
+
+// OdometerOverride lets an admin correct a car's mileage downwards, e.g.
+// after a mistyped reading or an instrument cluster replacement. Every use is
+// written to the odometer audit log.
+type OdometerOverride struct {
+	Reason string `json:"reason"`
+}
+
+// OdometerAudit records a mileage correction made with an override.
+type OdometerAudit struct {
+	ID              uuid.UUID `json:"id"`
+	CarID           uuid.UUID `json:"carId"`
+	PreviousMileage int64     `json:"previousMileage"`
+	NewMileage      int64     `json:"newMileage"`
+	Reason          string    `json:"reason"`
+	Username        string    `json:"username"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// ValidateUsedCarDetails checks mileage, condition grade, previous owners and
+// damage notes. New stock cannot have had an owner.
+func ValidateUsedCarDetails(carRequest CarRequest) error {
+	if carRequest.Mileage < 0 {
+		return errors.New("mileage must not be negative")
+	}
+	if carRequest.Mileage > maxMileage {
+		return errors.New(fmt.Sprintf("mileage must not exceed %d", maxMileage))
+	}
+	if err := ValidateCondition(carRequest.Condition); err != nil {
+		return err
+	}
+	if carRequest.PreviousOwners < 0 || carRequest.PreviousOwners > maxPreviousOwners {
+		return errors.New(fmt.Sprintf("previousOwners must be between 0 and %d", maxPreviousOwners))
+	}
+	if carRequest.Condition == ConditionNew && carRequest.PreviousOwners > 0 {
+		return errors.New("new cars must not have previous owners")
+	}
+	if len(carRequest.DamageNotes) > 2000 {
+		return errors.New("damageNotes must be at most 2000 characters")
+	}
+	return nil
+}
+
+func ValidateCondition(condition string) error {
+	if !contains(validConditions, condition) {
+		return errors.New(fmt.Sprintf("condition must be one of %v", validConditions))
+	}
+	return nil
+}
+
+func ValidateOdometerOverride(override OdometerOverride) error {
+	if override.Reason == "" {
+		return errors.New("odometerOverride reason is required")
+	}
+	if len(override.Reason) > 500 {
+		return errors.New("odometerOverride reason must be at most 500 characters")
+	}
+	return nil
+}
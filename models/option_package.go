@@ -0,0 +1,179 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var packageCodePattern = regexp.MustCompile(`^[A-Z0-9][A-Z0-9_-]{0,31}$`)
+
+var (
+	ErrPackageNotFound  = errors.New("package does not exist")
+	ErrPackageCodeTaken = errors.New("a package with this code is already offered")
+	// ErrInvalidConfiguration is wrapped by the rule violations of Configure.
+	ErrInvalidConfiguration = errors.New("invalid configuration")
+)
+
+// OptionPackage is an optional extra offered on a single car (CarID) or on
+// every car of a trim, identified by Brand and Model like reviews. A car's
+// own package replaces a trim package with the same Code. Requires and
+// Excludes list the codes of other packages.
+type OptionPackage struct {
+	ID          uuid.UUID  `json:"id"`
+	CarID       *uuid.UUID `json:"carId,omitempty"`
+	Brand       string     `json:"brand,omitempty"`
+	Model       string     `json:"model,omitempty"`
+	Code        string     `json:"code"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	PriceDelta  Money      `json:"priceDelta"`
+	Requires    []string   `json:"requires"`
+	Excludes    []string   `json:"excludes"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+type OptionPackageRequest struct {
+	CarID       *uuid.UUID `json:"carId"`
+	Brand       string     `json:"brand"`
+	Model       string     `json:"model"`
+	Code        string     `json:"code"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	PriceDelta  Money      `json:"priceDelta"`
+	Requires    []string   `json:"requires"`
+	Excludes    []string   `json:"excludes"`
+}
+
+type ConfigurationRequest struct {
+	Packages []string `json:"packages"`
+}
+
+// Configuration is a priced selection of packages on a car.
+type Configuration struct {
+	CarID          uuid.UUID       `json:"carId"`
+	BasePrice      Money           `json:"basePrice"`
+	Packages       []OptionPackage `json:"packages"`
+	TotalPrice     Money           `json:"totalPrice"`
+	ConvertedPrice *ConvertedPrice `json:"convertedPrice,omitempty"`
+}
+
+// Normalize upper-cases the package code and the codes in its rules.
+func (packageReq *OptionPackageRequest) Normalize() {
+	packageReq.Code = strings.ToUpper(strings.TrimSpace(packageReq.Code))
+	packageReq.Requires = normalizeCodes(packageReq.Requires)
+	packageReq.Excludes = normalizeCodes(packageReq.Excludes)
+	packageReq.PriceDelta.Currency = strings.ToUpper(packageReq.PriceDelta.Currency)
+}
+
+func normalizeCodes(codes []string) []string {
+	seen := make(map[string]bool, len(codes))
+	normalized := []string{}
+	for _, code := range codes {
+		code = strings.ToUpper(strings.TrimSpace(code))
+		if code == "" || seen[code] {
+			continue
+		}
+		seen[code] = true
+		normalized = append(normalized, code)
+	}
+	sort.Strings(normalized)
+	return normalized
+}
+
+func ValidateOptionPackageRequest(packageReq OptionPackageRequest) error {
+	hasModel := packageReq.Brand != "" || packageReq.Model != ""
+	if packageReq.CarID != nil && hasModel {
+		return errors.New("offer a package on either a carId or a brand and model, not both")
+	}
+	if packageReq.CarID == nil {
+		if strings.TrimSpace(packageReq.Brand) == "" || strings.TrimSpace(packageReq.Model) == "" {
+			return errors.New("carId or both brand and model are required")
+		}
+	} else if *packageReq.CarID == uuid.Nil {
+		return errors.New("carId is invalid")
+	}
+	if !packageCodePattern.MatchString(packageReq.Code) {
+		return errors.New("code must be 1 to 32 upper-case letters, digits, dashes or underscores")
+	}
+	if strings.TrimSpace(packageReq.Name) == "" {
+		return errors.New("name is required")
+	}
+	if packageReq.PriceDelta.Amount.Places() > 2 {
+		return errors.New("priceDelta must not have more than two decimal places")
+	}
+	if err := ValidateCurrency(packageReq.PriceDelta.Currency); err != nil {
+		return err
+	}
+	for _, code := range append(append([]string{}, packageReq.Requires...), packageReq.Excludes...) {
+		if code == packageReq.Code {
+			return errors.New("a package cannot require or exclude itself")
+		}
+		if !packageCodePattern.MatchString(code) {
+			return errors.New(fmt.Sprintf("invalid package code %q", code))
+		}
+	}
+	for _, code := range packageReq.Requires {
+		if contains(packageReq.Excludes, code) {
+			return errors.New(fmt.Sprintf("package %s cannot be both required and excluded", code))
+		}
+	}
+	return nil
+}
+
+// Configure prices the selected package codes on car. offered are the
+// packages available for the car; every selected package must be offered,
+// have all its requirements selected and exclude none of the others, in
+// either direction.
+func Configure(car Car, offered []OptionPackage, selection []string) (Configuration, error) {
+	byCode := make(map[string]OptionPackage, len(offered))
+	for _, pkg := range offered {
+		byCode[pkg.Code] = pkg
+	}
+
+	codes := normalizeCodes(selection)
+	selected := make(map[string]bool, len(codes))
+	for _, code := range codes {
+		if _, ok := byCode[code]; !ok {
+			return Configuration{}, errors.New(fmt.Sprintf("package %s is not offered for this car", code))
+		}
+		selected[code] = true
+	}
+
+	configuration := Configuration{
+		CarID:     car.ID,
+		BasePrice: car.Price,
+		Packages:  []OptionPackage{},
+	}
+	total := car.Price.Amount
+	for _, code := range codes {
+		pkg := byCode[code]
+		for _, required := range pkg.Requires {
+			if !selected[required] {
+				return Configuration{}, errors.New(fmt.Sprintf("package %s requires %s", code, required))
+			}
+		}
+		for _, excluded := range pkg.Excludes {
+			if selected[excluded] {
+				return Configuration{}, errors.New(fmt.Sprintf("package %s cannot be combined with %s", code, excluded))
+			}
+		}
+		if pkg.PriceDelta.Currency != car.Price.Currency {
+			return Configuration{}, errors.New(fmt.Sprintf("package %s is priced in %s but the car in %s",
+				code, pkg.PriceDelta.Currency, car.Price.Currency))
+		}
+		total = total.Add(pkg.PriceDelta.Amount)
+		configuration.Packages = append(configuration.Packages, pkg)
+	}
+	if total.Sign() <= 0 {
+		return Configuration{}, errors.New("the configured price must be greater than zero")
+	}
+
+	configuration.TotalPrice = NewMoney(total, car.Price.Currency)
+	return configuration, nil
+}
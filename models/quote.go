@@ -0,0 +1,193 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	QuoteTypeLoan  = "loan"
+	QuoteTypeLease = "lease"
+
+	// QuoteValidity is how long an issued quote is honoured.
+	QuoteValidity = 30 * 24 * time.Hour
+
+	maxQuoteTermMonths = 120
+)
+
+var quoteTypes = []string{QuoteTypeLoan, QuoteTypeLease}
+
+// ErrQuoteNotFound is also returned for quotes the caller may not read.
+var ErrQuoteNotFound = errors.New("quote does not exist")
+
+// QuoteRequest asks for a loan or lease quote on a car, optionally
+// configured with option packages. APR and ResidualPercent are percentages;
+// the residual is only used by leases.
+type QuoteRequest struct {
+	Type            string   `json:"type"`
+	DownPayment     Decimal  `json:"downPayment"`
+	TermMonths      int      `json:"termMonths"`
+	APR             Decimal  `json:"apr"`
+	ResidualPercent *Decimal `json:"residualPercent,omitempty"`
+	Packages        []string `json:"packages,omitempty"`
+}
+
+// AmortisationRow is one monthly payment of a quote and the balance left
+// after it.
+type AmortisationRow struct {
+	Month     int     `json:"month"`
+	Payment   Decimal `json:"payment"`
+	Interest  Decimal `json:"interest"`
+	Principal Decimal `json:"principal"`
+	Balance   Decimal `json:"balance"`
+}
+
+// Quote is an issued financing or lease offer. All amounts are in the
+// currency of the car's price at the time the quote was issued, and Price
+// includes the selected packages. TotalCost is
+// the down payment plus every monthly payment; for leases it excludes the
+// optional residual buyout.
+type Quote struct {
+	ID             uuid.UUID         `json:"id"`
+	CarID          uuid.UUID         `json:"carId"`
+	Username       string            `json:"username"`
+	Type           string            `json:"type"`
+	Price          Money             `json:"price"`
+	Packages       []string          `json:"packages"`
+	DownPayment    Decimal           `json:"downPayment"`
+	Financed       Decimal           `json:"financed"`
+	APR            Decimal           `json:"apr"`
+	TermMonths     int               `json:"termMonths"`
+	Residual       *Decimal          `json:"residual,omitempty"`
+	MonthlyPayment Decimal           `json:"monthlyPayment"`
+	TotalInterest  Decimal           `json:"totalInterest"`
+	TotalCost      Decimal           `json:"totalCost"`
+	Schedule       []AmortisationRow `json:"schedule"`
+	CreatedAt      time.Time         `json:"created_at"`
+	ExpiresAt      time.Time         `json:"expires_at"`
+}
+
+func ValidateQuoteRequest(quoteReq QuoteRequest, price Money) error {
+	if !contains(quoteTypes, quoteReq.Type) {
+		return errors.New(fmt.Sprintf("type must be one of %v", quoteTypes))
+	}
+	if quoteReq.TermMonths < 1 || quoteReq.TermMonths > maxQuoteTermMonths {
+		return errors.New(fmt.Sprintf("termMonths must be between 1 and %d", maxQuoteTermMonths))
+	}
+	if quoteReq.APR.Sign() < 0 || quoteReq.APR.Cmp(NewDecimal(100)) >= 0 {
+		return errors.New("apr must be at least 0 and less than 100")
+	}
+	if quoteReq.APR.Places() > 4 {
+		return errors.New("apr must not have more than four decimal places")
+	}
+	if quoteReq.DownPayment.Sign() < 0 {
+		return errors.New("downPayment must not be negative")
+	}
+	if quoteReq.DownPayment.Places() > 2 {
+		return errors.New("downPayment must not have more than two decimal places")
+	}
+	if quoteReq.DownPayment.Cmp(price.Amount) >= 0 {
+		return errors.New("downPayment must be less than the car price")
+	}
+
+	switch quoteReq.Type {
+	case QuoteTypeLease:
+		if quoteReq.ResidualPercent == nil {
+			return errors.New("residualPercent is required for leases")
+		}
+		residual := *quoteReq.ResidualPercent
+		if residual.Sign() <= 0 || residual.Cmp(NewDecimal(100)) >= 0 {
+			return errors.New("residualPercent must be greater than 0 and less than 100")
+		}
+		financed := price.Amount.Sub(quoteReq.DownPayment)
+		if residualAmount(price.Amount, residual).Cmp(financed) >= 0 {
+			return errors.New("the residual must be less than the financed amount")
+		}
+	case QuoteTypeLoan:
+		if quoteReq.ResidualPercent != nil {
+			return errors.New("residualPercent only applies to leases")
+		}
+	}
+	return nil
+}
+
+func residualAmount(price Decimal, residualPercent Decimal) Decimal {
+	return price.Mul(residualPercent).Quo(NewDecimal(100)).Round(2)
+}
+
+// BuildQuote prices a validated request against the car's price. The monthly
+// payment is the annuity that brings the financed amount down to the
+// residual (zero for loans) over the term; the final payment absorbs the
+// cent rounding of earlier months.
+func BuildQuote(car Car, quoteReq QuoteRequest, now time.Time) Quote {
+	price := car.Price.Amount
+	financed := price.Sub(quoteReq.DownPayment)
+	residual := NewDecimal(0)
+	quote := Quote{
+		CarID:       car.ID,
+		Type:        quoteReq.Type,
+		Price:       car.Price,
+		DownPayment: quoteReq.DownPayment,
+		Financed:    financed,
+		APR:         quoteReq.APR,
+		TermMonths:  quoteReq.TermMonths,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(QuoteValidity),
+	}
+	if quoteReq.Type == QuoteTypeLease {
+		residual = residualAmount(price, *quoteReq.ResidualPercent)
+		quote.Residual = &residual
+	}
+
+	monthlyRate := quoteReq.APR.Quo(NewDecimal(1200))
+	payment := annuityPayment(financed, residual, monthlyRate, quoteReq.TermMonths)
+
+	balance := financed
+	totalPaid := NewDecimal(0)
+	totalInterest := NewDecimal(0)
+	quote.Schedule = make([]AmortisationRow, 0, quoteReq.TermMonths)
+	for month := 1; month <= quoteReq.TermMonths; month++ {
+		interest := balance.Mul(monthlyRate).Round(2)
+		principal := payment.Sub(interest)
+		if month == quoteReq.TermMonths {
+			principal = balance.Sub(residual)
+		}
+		rowPayment := interest.Add(principal)
+		balance = balance.Sub(principal)
+
+		totalPaid = totalPaid.Add(rowPayment)
+		totalInterest = totalInterest.Add(interest)
+		quote.Schedule = append(quote.Schedule, AmortisationRow{
+			Month:     month,
+			Payment:   rowPayment,
+			Interest:  interest,
+			Principal: principal,
+			Balance:   balance,
+		})
+	}
+
+	quote.MonthlyPayment = payment
+	quote.TotalInterest = totalInterest
+	quote.TotalCost = quoteReq.DownPayment.Add(totalPaid)
+	return quote
+}
+
+// annuityPayment returns the level monthly payment, rounded to cents, that
+// amortises principal down to residual over months at the given monthly
+// rate.
+func annuityPayment(principal, residual, rate Decimal, months int) Decimal {
+	if rate.IsZero() {
+		return principal.Sub(residual).Quo(NewDecimal(int64(months))).Round(2)
+	}
+	growth := NewDecimal(1)
+	onePlusRate := NewDecimal(1).Add(rate)
+	for i := 0; i < months; i++ {
+		growth = growth.Mul(onePlusRate)
+	}
+	// payment = (principal * (1+r)^n - residual) * r / ((1+r)^n - 1)
+	numerator := principal.Mul(growth).Sub(residual).Mul(rate)
+	return numerator.Quo(growth.Sub(NewDecimal(1))).Round(2)
+}
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ReviewStatusPending  = "pending"
+	ReviewStatusApproved = "approved"
+	ReviewStatusRejected = "rejected"
+
+	maxReviewTextLength = 5000
+)
+
+var reviewStatuses = []string{ReviewStatusPending, ReviewStatusApproved, ReviewStatusRejected}
+
+// Review rates either a single car (CarID) or a whole model, identified by
+// Brand and Model, which match a car's brand and name.
+type Review struct {
+	ID        uuid.UUID  `json:"id"`
+	Username  string     `json:"username"`
+	CarID     *uuid.UUID `json:"carId,omitempty"`
+	Brand     string     `json:"brand,omitempty"`
+	Model     string     `json:"model,omitempty"`
+	Rating    int        `json:"rating"`
+	Text      string     `json:"text"`
+	Status    string     `json:"status"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+type ReviewRequest struct {
+	CarID  *uuid.UUID `json:"carId"`
+	Brand  string     `json:"brand"`
+	Model  string     `json:"model"`
+	Rating int        `json:"rating"`
+	Text   string     `json:"text"`
+}
+
+type ModerationRequest struct {
+	Status string `json:"status"`
+}
+
+// Rating summarises the approved reviews of a car and of its model.
+type Rating struct {
+	Average float64 `json:"average"`
+	Count   int64   `json:"count"`
+}
+
+func ValidateReviewRequest(reviewReq ReviewRequest) error {
+	hasModel := reviewReq.Brand != "" || reviewReq.Model != ""
+	if reviewReq.CarID != nil && hasModel {
+		return errors.New("review either a carId or a brand and model, not both")
+	}
+	if reviewReq.CarID == nil {
+		if strings.TrimSpace(reviewReq.Brand) == "" || strings.TrimSpace(reviewReq.Model) == "" {
+			return errors.New("carId or both brand and model are required")
+		}
+	} else if *reviewReq.CarID == uuid.Nil {
+		return errors.New("carId is invalid")
+	}
+	if reviewReq.Rating < 1 || reviewReq.Rating > 5 {
+		return errors.New("rating must be between 1 and 5")
+	}
+	if len(reviewReq.Text) > maxReviewTextLength {
+		return errors.New(fmt.Sprintf("text must be at most %d characters", maxReviewTextLength))
+	}
+	return nil
+}
+
+func ValidateReviewStatus(status string) error {
+	if !contains(reviewStatuses, status) {
+		return errors.New(fmt.Sprintf("status must be one of %v", reviewStatuses))
+	}
+	return nil
+}
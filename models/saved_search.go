@@ -0,0 +1,118 @@
+package models
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	CarEventCreated = "created"
+	CarEventUpdated = "updated"
+)
+
+// CarFilter describes the cars a saved search is interested in. Empty fields
+// match any car. Price bounds are inclusive and, when Currency is set, only
+// apply to cars listed in that currency.
+type CarFilter struct {
+	Brand    string   `json:"brand,omitempty"`
+	FuelType string   `json:"fuelType,omitempty"`
+	MinPrice *Decimal `json:"minPrice,omitempty"`
+	MaxPrice *Decimal `json:"maxPrice,omitempty"`
+	Currency string   `json:"currency,omitempty"`
+	MinYear  *int     `json:"minYear,omitempty"`
+	MaxYear  *int     `json:"maxYear,omitempty"`
+}
+
+type SavedSearch struct {
+	ID        uuid.UUID `json:"id"`
+	Username  string    `json:"username"`
+	Name      string    `json:"name"`
+	Filter    CarFilter `json:"filter"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type SavedSearchRequest struct {
+	Name   string    `json:"name"`
+	Filter CarFilter `json:"filter"`
+}
+
+type Notification struct {
+	ID            uuid.UUID `json:"id"`
+	Username      string    `json:"username"`
+	SavedSearchID uuid.UUID `json:"savedSearchId"`
+	SearchName    string    `json:"searchName"`
+	CarID         uuid.UUID `json:"carId"`
+	Event         string    `json:"event"`
+	Read          bool      `json:"read"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+func ValidateSavedSearchRequest(searchReq SavedSearchRequest) error {
+	if strings.TrimSpace(searchReq.Name) == "" {
+		return errors.New("name is required")
+	}
+	if len(searchReq.Name) > 100 {
+		return errors.New("name must be at most 100 characters")
+	}
+	return ValidateCarFilter(searchReq.Filter)
+}
+
+func ValidateCarFilter(filter CarFilter) error {
+	if filter == (CarFilter{}) {
+		return errors.New("filter must have at least one criterion")
+	}
+	if filter.FuelType != "" {
+		if err := ValidateFuelType(filter.FuelType); err != nil {
+			return err
+		}
+	}
+	if filter.Currency != "" {
+		if err := ValidateCurrency(filter.Currency); err != nil {
+			return err
+		}
+	}
+	if filter.MinPrice != nil && filter.MaxPrice != nil && filter.MinPrice.Cmp(*filter.MaxPrice) > 0 {
+		return errors.New("minPrice must not be greater than maxPrice")
+	}
+	if filter.MinYear != nil && filter.MaxYear != nil && *filter.MinYear > *filter.MaxYear {
+		return errors.New("minYear must not be greater than maxYear")
+	}
+	return nil
+}
+
+func (filter CarFilter) Matches(car Car) bool {
+	if filter.Brand != "" && !strings.EqualFold(filter.Brand, car.Brand) {
+		return false
+	}
+	if filter.FuelType != "" && filter.FuelType != car.FuelType {
+		return false
+	}
+	if filter.MinPrice != nil || filter.MaxPrice != nil {
+		if filter.Currency != "" && filter.Currency != car.Price.Currency {
+			return false
+		}
+		if filter.MinPrice != nil && car.Price.Amount.Cmp(*filter.MinPrice) < 0 {
+			return false
+		}
+		if filter.MaxPrice != nil && car.Price.Amount.Cmp(*filter.MaxPrice) > 0 {
+			return false
+		}
+	}
+	if filter.MinYear != nil || filter.MaxYear != nil {
+		year, err := strconv.Atoi(car.Year)
+		if err != nil {
+			return false
+		}
+		if filter.MinYear != nil && year < *filter.MinYear {
+			return false
+		}
+		if filter.MaxYear != nil && year > *filter.MaxYear {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,115 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultServiceIntervalDays     = 365
+	DefaultServiceIntervalDistance = 15000
+)
+
+type ServiceRecord struct {
+	ID          uuid.UUID `json:"id"`
+	CarID       uuid.UUID `json:"carId"`
+	ServiceDate string    `json:"serviceDate"`
+	Odometer    int64     `json:"odometer"`
+	WorkDone    string    `json:"workDone"`
+	Parts       []string  `json:"parts"`
+	Cost        Money     `json:"cost"`
+	Workshop    string    `json:"workshop"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type ServiceRecordRequest struct {
+	ServiceDate string   `json:"serviceDate"`
+	Odometer    int64    `json:"odometer"`
+	WorkDone    string   `json:"workDone"`
+	Parts       []string `json:"parts"`
+	Cost        Money    `json:"cost"`
+	Workshop    string   `json:"workshop"`
+}
+
+// ServiceDue explains why a car needs a service. LastService is nil for
+// cars without any service history.
+type ServiceDue struct {
+	CarID                uuid.UUID      `json:"carId"`
+	Name                 string         `json:"name"`
+	Brand                string         `json:"brand"`
+	LastService          *ServiceRecord `json:"lastService"`
+	DaysSinceService     *int           `json:"daysSinceService,omitempty"`
+	DistanceSinceService *int64         `json:"distanceSinceService,omitempty"`
+	Reasons              []string       `json:"reasons"`
+}
+
+func ValidateServiceRecordRequest(recordReq ServiceRecordRequest) error {
+	serviceDate, err := time.Parse(DateLayout, recordReq.ServiceDate)
+	if err != nil {
+		return errors.New("serviceDate must be formatted as YYYY-MM-DD")
+	}
+	if serviceDate.After(time.Now()) {
+		return errors.New("serviceDate must not be in the future")
+	}
+	if recordReq.Odometer < 0 {
+		return errors.New("odometer must not be negative")
+	}
+	if strings.TrimSpace(recordReq.WorkDone) == "" {
+		return errors.New("workDone is required")
+	}
+	if strings.TrimSpace(recordReq.Workshop) == "" {
+		return errors.New("workshop is required")
+	}
+	if recordReq.Cost.Amount.Sign() < 0 {
+		return errors.New("cost must not be negative")
+	}
+	if recordReq.Cost.Amount.Places() > 2 {
+		return errors.New("cost must not have more than two decimal places")
+	}
+	if err := ValidateCurrency(recordReq.Cost.Currency); err != nil {
+		return err
+	}
+	return nil
+}
+
+// CheckServiceDue decides whether a car is due for service given its most
+// recent record. The distance rule only applies when the car's current
+// odometer reading is known and not behind the serviced reading.
+func CheckServiceDue(last *ServiceRecord, currentOdometer *int64, now time.Time, maxDays int, maxDistance int64) (ServiceDue, bool) {
+	var due ServiceDue
+	if last == nil {
+		due.Reasons = []string{"no service history"}
+		return due, true
+	}
+	due.LastService = last
+
+	if serviceDate, err := time.Parse(DateLayout, last.ServiceDate); err == nil {
+		days := int(now.Sub(serviceDate).Hours() / 24)
+		due.DaysSinceService = &days
+		if days >= maxDays {
+			due.Reasons = append(due.Reasons, fmt.Sprintf("%d days since last service", days))
+		}
+	}
+	if currentOdometer != nil && *currentOdometer >= last.Odometer {
+		distance := *currentOdometer - last.Odometer
+		due.DistanceSinceService = &distance
+		if distance >= maxDistance {
+			due.Reasons = append(due.Reasons, fmt.Sprintf("%d km since last service", distance))
+		}
+	}
+	return due, len(due.Reasons) > 0
+}
+
+func ValidateServiceInterval(maxDays int, maxDistance int64) error {
+	if maxDays <= 0 {
+		return errors.New("days must be greater than zero")
+	}
+	if maxDistance <= 0 {
+		return errors.New("distance must be greater than zero")
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TokenPair is returned by login and refresh. The access token is a
+// short-lived JWT; the refresh token is an opaque secret that can be
+// exchanged once for a new pair.
+type TokenPair struct {
+	AccessToken      string    `json:"token"`
+	TokenType        string    `json:"tokenType"`
+	ExpiresIn        int64     `json:"expiresIn"`
+	RefreshToken     string    `json:"refreshToken"`
+	RefreshExpiresAt time.Time `json:"refreshExpiresAt"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+type LogoutRequest struct {
+	// AllSessions signs the user out everywhere, not only on this device.
+	AllSessions bool `json:"allSessions"`
+}
+
+// RefreshToken is the server-side record of an issued refresh token. Only a
+// hash of the secret is kept. Tokens of one login share a SessionID across
+// rotations; AccessJTI is the access token issued alongside, so revoking the
+// session can revoke it too.
+type RefreshToken struct {
+	ID              uuid.UUID
+	SessionID       uuid.UUID
+	Username        string
+	TokenHash       string
+	AccessJTI       string
+	AccessExpiresAt time.Time
+	ExpiresAt       time.Time
+	CreatedAt       time.Time
+	UsedAt          *time.Time
+	RevokedAt       *time.Time
+}
+
+var (
+	ErrInvalidRefreshToken = errors.New("refresh token is invalid or expired")
+	// ErrRefreshTokenReused means an already rotated refresh token was
+	// presented again, which suggests it was stolen.
+	ErrRefreshTokenReused = errors.New("refresh token was already used")
+	// ErrNoSession is returned when logging out with a token that was not
+	// issued for a login session.
+	ErrNoSession = errors.New("token does not belong to a session")
+)
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+const (
+	DefaultSimilarLimit = 5
+	MaxSimilarLimit     = 50
+)
+
+// SimilarityWeights sets how much each aspect of a car counts towards its
+// similarity score. Weights are relative to each other.
+type SimilarityWeights struct {
+	Price    float64 `json:"price"`
+	FuelType float64 `json:"fuelType"`
+	Brand    float64 `json:"brand"`
+	Year     float64 `json:"year"`
+	Engine   float64 `json:"engine"`
+}
+
+var DefaultSimilarityWeights = SimilarityWeights{
+	Price:    3,
+	FuelType: 2,
+	Brand:    1,
+	Year:     1,
+	Engine:   2,
+}
+
+type SimilarCar struct {
+	Car       Car                `json:"car"`
+	Score     float64            `json:"score"`
+	Breakdown map[string]float64 `json:"breakdown"`
+}
+
+func ValidateSimilarityWeights(weights SimilarityWeights) error {
+	all := []float64{weights.Price, weights.FuelType, weights.Brand, weights.Year, weights.Engine}
+	var total float64
+	for _, w := range all {
+		if math.IsNaN(w) || math.IsInf(w, 0) {
+			return errors.New("similarity weights must be finite numbers")
+		}
+		if w < 0 {
+			return errors.New("similarity weights must not be negative")
+		}
+		total += w
+	}
+	if total == 0 {
+		return errors.New("at least one similarity weight must be greater than zero")
+	}
+	if math.IsInf(total, 0) {
+		return errors.New("similarity weights are too large")
+	}
+	return nil
+}
+
+func ValidateSimilarLimit(limit int) error {
+	if limit < 1 || limit > MaxSimilarLimit {
+		return errors.New(fmt.Sprintf("limit must be between 1 and %d", MaxSimilarLimit))
+	}
+	return nil
+}
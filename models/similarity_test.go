@@ -0,0 +1,29 @@
+package models
+
+import (
+	"math"
+	"testing"
+)
+
+func TestValidateSimilarityWeightsRejectsNonFiniteWeights(t *testing.T) {
+	for name, weight := range map[string]float64{
+		"NaN":               math.NaN(),
+		"positive infinity": math.Inf(1),
+		"negative infinity": math.Inf(-1),
+	} {
+		weights := DefaultSimilarityWeights
+		weights.Price = weight
+		if err := ValidateSimilarityWeights(weights); err == nil {
+			t.Errorf("%s weight was accepted", name)
+		}
+	}
+
+	weights := DefaultSimilarityWeights
+	weights.Price, weights.Brand = math.MaxFloat64, math.MaxFloat64
+	if err := ValidateSimilarityWeights(weights); err == nil {
+		t.Error("weights adding up to infinity were accepted")
+	}
+	if err := ValidateSimilarityWeights(DefaultSimilarityWeights); err != nil {
+		t.Errorf("default weights: %v", err)
+	}
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"regexp"
+)
+
+// DefaultTenant owns every row created before tenancy was introduced. Its
+// admins operate the platform and may create users in other tenants.
+const DefaultTenant = "default"
+
+var tenantPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,63}$`)
+
+var ErrNoTenant = errors.New("the request is not bound to a tenant")
+
+type tenantContextKey struct{}
+
+// WithTenant binds ctx to a tenant. AuthMiddleware binds every authenticated
+// request; the tenant-scoped stores read it back with TenantFromContext.
+func WithTenant(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, tenantID)
+}
+
+// TenantFromContext returns the tenant ctx is bound to, or an empty string.
+func TenantFromContext(ctx context.Context) string {
+	tenantID, _ := ctx.Value(tenantContextKey{}).(string)
+	return tenantID
+}
+
+func ValidateTenantID(tenantID string) error {
+	if !tenantPattern.MatchString(tenantID) {
+		return errors.New("tenantId must be 1 to 64 lower-case letters, digits or dashes")
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	RoleViewer = "viewer"
+	RoleEditor = "editor"
+	RoleAdmin  = "admin"
+
+	minPasswordLength = 10
+	// maxPasswordLength is bcrypt's input limit in bytes.
+	maxPasswordLength = 72
+)
+
+var (
+	usernamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{2,63}$`)
+
+	// roles lists the roles from least to most privileged; each role may do
+	// everything the roles before it may.
+	roles = []string{RoleViewer, RoleEditor, RoleAdmin}
+)
+
+type User struct {
+	ID           uuid.UUID `json:"id"`
+	Username     string    `json:"username"`
+	PasswordHash string    `json:"-"`
+	Role         string    `json:"role"`
+	TenantID     string    `json:"tenantId"`
+	Location     string    `json:"location"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+type UserRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Role     string `json:"role"`
+	// TenantID defaults to the creating admin's tenant.
+	TenantID string `json:"tenantId"`
+	// Location is the dealership location whose cars an editor may modify.
+	Location string `json:"location"`
+}
+
+// Normalize lower-cases the username so logins are case-insensitive and
+// makes new users viewers unless a role is given.
+func (userReq *UserRequest) Normalize() {
+	userReq.Username = strings.ToLower(strings.TrimSpace(userReq.Username))
+	userReq.Location = strings.TrimSpace(userReq.Location)
+	if userReq.Role == "" {
+		userReq.Role = RoleViewer
+	}
+}
+
+func ValidateUserRequest(userReq UserRequest) error {
+	if err := ValidateUsername(userReq.Username); err != nil {
+		return err
+	}
+	if err := ValidateRole(userReq.Role); err != nil {
+		return err
+	}
+	if err := ValidateTenantID(userReq.TenantID); err != nil {
+		return err
+	}
+	if err := ValidateLocation(userReq.Location); err != nil {
+		return err
+	}
+	return ValidatePassword(userReq.Password)
+}
+
+func ValidateUsername(username string) error {
+	if !usernamePattern.MatchString(username) {
+		return errors.New("username must be 3 to 64 lower-case letters, digits, dots, dashes or underscores")
+	}
+	return nil
+}
+
+var (
+	ErrPasswordTooShort = errors.New("password must be at least 10 characters")
+	ErrPasswordTooLong  = errors.New("password must be at most 72 bytes")
+)
+
+func ValidatePassword(password string) error {
+	if len(password) < minPasswordLength {
+		return ErrPasswordTooShort
+	}
+	if len(password) > maxPasswordLength {
+		return ErrPasswordTooLong
+	}
+	return nil
+}
+
+var (
+	ErrUserNotFound       = errors.New("user does not exist")
+	ErrInvalidCredentials = errors.New("invalid username or password")
+
+	ErrInvalidIDToken              = errors.New("ID token is invalid")
+	ErrIdentityProviderUnavailable = errors.New("the identity provider cannot be reached")
+	ErrNoRole                      = errors.New("your groups do not grant access to CarZone")
+	ErrUsernameTaken               = errors.New("username is already taken by another account")
+)
+
+func ValidateRole(role string) error {
+	if !contains(roles, role) {
+		return fmt.Errorf("role must be one of %v", roles)
+	}
+	return nil
+}
+
+// RoleAtLeast reports whether role grants everything required does. Unknown
+// roles, including the empty role of older tokens, grant nothing beyond
+// viewer.
+func RoleAtLeast(role string, required string) bool {
+	rank := 0
+	for i, r := range roles {
+		if r == role {
+			rank = i
+		}
+	}
+	for i, r := range roles {
+		if r == required {
+			return rank >= i
+		}
+	}
+	return false
+}
+
+// AccessDenial records a request refused because the caller's role was
+// insufficient.
+type AccessDenial struct {
+	ID           uuid.UUID `json:"id"`
+	Username     string    `json:"username"`
+	Role         string    `json:"role"`
+	RequiredRole string    `json:"requiredRole"`
+	Method       string    `json:"method"`
+	Path         string    `json:"path"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// OIDCIdentity is the identity asserted by an external OpenID Connect
+// issuer's ID token.
+type OIDCIdentity struct {
+	Subject           string
+	PreferredUsername string
+	Email             string
+	Groups            []string
+}
+
+type OIDCLoginRequest struct {
+	IDToken string `json:"idToken"`
+	Nonce   string `json:"nonce"`
+}
@@ -0,0 +1,165 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// DefaultCurveBrand names the curve used for brands without their own.
+	DefaultCurveBrand = "*"
+
+	ValuationDepreciation = "depreciation"
+	ValuationMileage      = "mileage"
+
+	maxCurveYears = 30
+)
+
+// maxMileageAdjustment caps the mileage adjustment at 30% of the depreciated
+// value in either direction, so extreme odometer readings cannot zero out or
+// double an estimate.
+var maxMileageAdjustment = MustParseDecimal("0.3")
+
+// DepreciationCurve describes how cars of a brand lose value. YearlyRates[i]
+// is the fraction of the remaining value lost in year i+1 of the car's life;
+// the last rate repeats for older cars. MileageRate is the fraction of value
+// added or removed per 1,000 km below or above AnnualMileage per year of age.
+type DepreciationCurve struct {
+	Brand         string    `json:"brand"`
+	YearlyRates   []Decimal `json:"yearlyRates"`
+	AnnualMileage int64     `json:"annualMileage"`
+	MileageRate   Decimal   `json:"mileageRate"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type DepreciationCurveRequest struct {
+	YearlyRates   []Decimal `json:"yearlyRates"`
+	AnnualMileage int64     `json:"annualMileage"`
+	MileageRate   Decimal   `json:"mileageRate"`
+}
+
+// ValuationAdjustment is one step of a valuation. Rate and Amount are
+// negative for adjustments that lower the value.
+type ValuationAdjustment struct {
+	Kind        string  `json:"kind"`
+	Description string  `json:"description"`
+	Rate        Decimal `json:"rate"`
+	Amount      Money   `json:"amount"`
+	ValueAfter  Money   `json:"valueAfter"`
+}
+
+type Valuation struct {
+	CarID          uuid.UUID             `json:"carId"`
+	ListPrice      Money                 `json:"listPrice"`
+	Year           int                   `json:"year"`
+	AgeYears       int                   `json:"ageYears"`
+	Mileage        *int64                `json:"mileage,omitempty"`
+	CurveBrand     string                `json:"curveBrand"`
+	Adjustments    []ValuationAdjustment `json:"adjustments"`
+	EstimatedValue Money                 `json:"estimatedValue"`
+	ConvertedValue *ConvertedPrice       `json:"convertedValue,omitempty"`
+}
+
+func ValidateDepreciationCurveRequest(curveReq DepreciationCurveRequest) error {
+	if len(curveReq.YearlyRates) == 0 || len(curveReq.YearlyRates) > maxCurveYears {
+		return errors.New(fmt.Sprintf("yearlyRates must have between 1 and %d entries", maxCurveYears))
+	}
+	for i, rate := range curveReq.YearlyRates {
+		if err := validateFraction(fmt.Sprintf("yearlyRates[%d]", i), rate); err != nil {
+			return err
+		}
+	}
+	if curveReq.AnnualMileage <= 0 {
+		return errors.New("annualMileage must be greater than zero")
+	}
+	return validateFraction("mileageRate", curveReq.MileageRate)
+}
+
+func validateFraction(name string, value Decimal) error {
+	if value.Sign() < 0 || value.Cmp(NewDecimal(1)) >= 0 {
+		return errors.New(name + " must be at least 0 and less than 1")
+	}
+	if value.Places() > 4 {
+		return errors.New(name + " must not have more than four decimal places")
+	}
+	return nil
+}
+
+// Valuate estimates the current value of car from its list price by applying
+// one depreciation step per year of age and then a mileage adjustment when
+// the car has a recorded mileage. A car from the current year is expected to
+// have covered one year of mileage. Each step is rounded to cents so the
+// breakdown adds up to the estimate.
+func Valuate(car Car, curve DepreciationCurve, now time.Time) (Valuation, error) {
+	year, err := strconv.Atoi(car.Year)
+	if err != nil {
+		return Valuation{}, errors.New("car year must be a valid number")
+	}
+	age := now.Year() - year
+	if age < 0 {
+		age = 0
+	}
+
+	valuation := Valuation{
+		CarID:       car.ID,
+		ListPrice:   car.Price,
+		Year:        year,
+		AgeYears:    age,
+		CurveBrand:  curve.Brand,
+		Adjustments: []ValuationAdjustment{},
+	}
+	currency := car.Price.Currency
+	value := car.Price.Amount
+
+	for i := 0; i < age; i++ {
+		rate := curve.YearlyRates[len(curve.YearlyRates)-1]
+		if i < len(curve.YearlyRates) {
+			rate = curve.YearlyRates[i]
+		}
+		amount := value.Mul(rate).Round(2)
+		value = value.Sub(amount)
+		valuation.Adjustments = append(valuation.Adjustments, ValuationAdjustment{
+			Kind:        ValuationDepreciation,
+			Description: fmt.Sprintf("year %d depreciation", i+1),
+			Rate:        NewDecimal(0).Sub(rate),
+			Amount:      NewMoney(NewDecimal(0).Sub(amount), currency),
+			ValueAfter:  NewMoney(value, currency),
+		})
+	}
+
+	if car.Mileage > 0 {
+		mileage := car.Mileage
+		valuation.Mileage = &mileage
+
+		expected := int64(age) * curve.AnnualMileage
+		if age == 0 {
+			expected = curve.AnnualMileage
+		}
+		deviation := expected - mileage
+		rate := curve.MileageRate.Mul(NewDecimal(deviation)).Mul(MustParseDecimal("0.001")).Round(4)
+		if rate.Cmp(maxMileageAdjustment) > 0 {
+			rate = maxMileageAdjustment
+		}
+		if rate.Cmp(NewDecimal(0).Sub(maxMileageAdjustment)) < 0 {
+			rate = NewDecimal(0).Sub(maxMileageAdjustment)
+		}
+		if !rate.IsZero() {
+			amount := value.Mul(rate).Round(2)
+			value = value.Add(amount)
+			valuation.Adjustments = append(valuation.Adjustments, ValuationAdjustment{
+				Kind:        ValuationMileage,
+				Description: fmt.Sprintf("%d km driven against %d km expected", mileage, expected),
+				Rate:        rate,
+				Amount:      NewMoney(amount, currency),
+				ValueAfter:  NewMoney(value, currency),
+			})
+		}
+	}
+
+	valuation.EstimatedValue = NewMoney(value, currency)
+	return valuation, nil
+}
@@ -0,0 +1,41 @@
+// Package notify delivers password reset tokens during development, where
+// no mail service is available. Production deployments plug in their own
+// service.Notifier.
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gloonch/CarZone/models"
+)
+
+// LogNotifier writes reset tokens to the application log.
+type LogNotifier struct{}
+
+func (LogNotifier) NotifyPasswordReset(ctx context.Context, user models.User, token string, expiresAt time.Time) error {
+	log.Printf("Password reset token for %s, valid until %s: %s", user.Username, expiresAt.Format(time.RFC3339), token)
+	return nil
+}
+
+// FileNotifier writes the latest reset token of each user to
+// <dir>/<username>.password-reset, readable only by the server's user.
+type FileNotifier struct {
+	dir string
+}
+
+func NewFileNotifier(dir string) (*FileNotifier, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &FileNotifier{dir: dir}, nil
+}
+
+func (n *FileNotifier) NotifyPasswordReset(ctx context.Context, user models.User, token string, expiresAt time.Time) error {
+	content := fmt.Sprintf("token: %s\nexpires: %s\n", token, expiresAt.Format(time.RFC3339))
+	return os.WriteFile(filepath.Join(n.dir, user.Username+".password-reset"), []byte(content), 0o600)
+}
@@ -0,0 +1,127 @@
+// Package oidctest runs a fake OpenID Connect issuer in-process, so the OIDC
+// login flow can be exercised without a real identity provider.
+package oidctest
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"github.com/gloonch/CarZone/models"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// Issuer serves a discovery document and a JWKS and signs ID tokens with
+// ES256.
+type Issuer struct {
+	server *httptest.Server
+
+	mu     sync.Mutex
+	keyID  int
+	key    *ecdsa.PrivateKey
+	oldKey *ecdsa.PrivateKey
+}
+
+func NewIssuer() (*Issuer, error) {
+	issuer := &Issuer{}
+	if err := issuer.RotateKey(); err != nil {
+		return nil, err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", issuer.discovery)
+	mux.HandleFunc("/jwks", issuer.jwks)
+	issuer.server = httptest.NewServer(mux)
+	return issuer, nil
+}
+
+// URL is the issuer identifier, to be used as the OIDC issuer URL.
+func (i *Issuer) URL() string {
+	return i.server.URL
+}
+
+func (i *Issuer) Close() {
+	i.server.Close()
+}
+
+// RotateKey starts signing with a new key. The previous key stays in the
+// JWKS, like at a real issuer during rotation.
+func (i *Issuer) RotateKey() error {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return err
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.oldKey, i.key = i.key, key
+	i.keyID++
+	return nil
+}
+
+// IDToken signs an ID token for the subject. iss, sub, aud, iat and exp are
+// filled in; extra claims such as groups, preferred_username or nonce are
+// added as given and may override them.
+func (i *Issuer) IDToken(subject string, audience string, extra map[string]interface{}) (string, error) {
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss": i.URL(),
+		"sub": subject,
+		"aud": audience,
+		"iat": now.Unix(),
+		"exp": now.Add(5 * time.Minute).Unix(),
+	}
+	for name, value := range extra {
+		claims[name] = value
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+	token.Header["kid"] = fmt.Sprintf("key-%d", i.keyID)
+	return token.SignedString(i.key)
+}
+
+func (i *Issuer) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, map[string]interface{}{
+		"issuer":                                i.URL(),
+		"jwks_uri":                              i.URL() + "/jwks",
+		"id_token_signing_alg_values_supported": []string{"ES256"},
+	})
+}
+
+func (i *Issuer) jwks(w http.ResponseWriter, r *http.Request) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	jwks := models.JWKS{Keys: []models.JWK{jwk(fmt.Sprintf("key-%d", i.keyID), i.key)}}
+	if i.oldKey != nil {
+		jwks.Keys = append(jwks.Keys, jwk(fmt.Sprintf("key-%d", i.keyID-1), i.oldKey))
+	}
+	writeJSON(w, jwks)
+}
+
+func jwk(id string, key *ecdsa.PrivateKey) models.JWK {
+	ecdhKey, _ := key.PublicKey.ECDH()
+	point := ecdhKey.Bytes()
+	return models.JWK{
+		KeyType:   "EC",
+		KeyID:     id,
+		Use:       "sig",
+		Algorithm: "ES256",
+		Curve:     "P-256",
+		X:         base64.RawURLEncoding.EncodeToString(point[1:33]),
+		Y:         base64.RawURLEncoding.EncodeToString(point[33:]),
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,263 @@
+package oidc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gloonch/CarZone/models"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const (
+	defaultJWKSCacheTTL        = time.Hour
+	defaultJWKSRefreshInterval = time.Minute
+)
+
+type Config struct {
+	// IssuerURL must equal the iss claim of the issuer's tokens.
+	IssuerURL string
+	// ClientID is CarZone's client at the issuer; tokens must name it in aud.
+	ClientID string
+	// GroupsClaim is the claim listing the user's groups. Defaults to
+	// "groups".
+	GroupsClaim  string
+	JWKSCacheTTL time.Duration
+	// JWKSRefreshInterval is the least time between refetching the JWKS for
+	// tokens signed with an unknown key, so forged kids cannot make us
+	// hammer the issuer. Defaults to a minute.
+	JWKSRefreshInterval time.Duration
+	HTTPClient          *http.Client
+}
+
+type discoveryDocument struct {
+	Issuer  string `json:"issuer"`
+	JWKSURI string `json:"jwks_uri"`
+}
+
+// Provider verifies ID tokens of one OpenID Connect issuer. The discovery
+// document is fetched on first use and the issuer's keys are cached.
+type Provider struct {
+	config Config
+
+	mu        sync.Mutex
+	jwksURI   string
+	keys      map[string]interface{}
+	fetchedAt time.Time
+}
+
+func NewProvider(config Config) (*Provider, error) {
+	if config.IssuerURL == "" || config.ClientID == "" {
+		return nil, errors.New("an issuer URL and a client ID are required")
+	}
+	if config.GroupsClaim == "" {
+		config.GroupsClaim = "groups"
+	}
+	if config.JWKSCacheTTL <= 0 {
+		config.JWKSCacheTTL = defaultJWKSCacheTTL
+	}
+	if config.JWKSRefreshInterval <= 0 {
+		config.JWKSRefreshInterval = defaultJWKSRefreshInterval
+	}
+	if config.HTTPClient == nil {
+		config.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &Provider{
+		config: config,
+	}, nil
+}
+
+// Verify checks the signature, issuer, audience and lifetime of an ID token
+// and returns the identity it asserts. A non-empty nonce must match the
+// token's nonce claim.
+func (p *Provider) Verify(ctx context.Context, rawIDToken string, nonce string) (*models.OIDCIdentity, error) {
+	parser := jwt.NewParser(jwt.WithValidMethods([]string{"RS256", "ES256"}))
+	claims := jwt.MapClaims{}
+	_, err := parser.ParseWithClaims(rawIDToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.key(ctx, kid)
+	})
+	if err != nil {
+		if errors.Is(err, models.ErrIdentityProviderUnavailable) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %v", models.ErrInvalidIDToken, err)
+	}
+
+	now := time.Now().Unix()
+	if !claims.VerifyIssuer(p.config.IssuerURL, true) {
+		return nil, fmt.Errorf("%w: unexpected issuer", models.ErrInvalidIDToken)
+	}
+	if !claims.VerifyAudience(p.config.ClientID, true) {
+		return nil, fmt.Errorf("%w: token is not for this client", models.ErrInvalidIDToken)
+	}
+	if azp, ok := claims["azp"].(string); ok && azp != p.config.ClientID {
+		return nil, fmt.Errorf("%w: token was issued to another client", models.ErrInvalidIDToken)
+	}
+	if !claims.VerifyExpiresAt(now, true) || !claims.VerifyIssuedAt(now, true) {
+		return nil, fmt.Errorf("%w: token is expired or lacks exp or iat", models.ErrInvalidIDToken)
+	}
+	if nonce != "" && stringClaim(claims, "nonce") != nonce {
+		return nil, fmt.Errorf("%w: nonce does not match", models.ErrInvalidIDToken)
+	}
+
+	identity := &models.OIDCIdentity{
+		Subject:           stringClaim(claims, "sub"),
+		PreferredUsername: stringClaim(claims, "preferred_username"),
+		Email:             stringClaim(claims, "email"),
+		Groups:            stringsClaim(claims, p.config.GroupsClaim),
+	}
+	if identity.Subject == "" {
+		return nil, fmt.Errorf("%w: token has no subject", models.ErrInvalidIDToken)
+	}
+	return identity, nil
+}
+
+// key returns the issuer's key with the given ID, refreshing the cached JWKS
+// when it is stale or does not know the key.
+func (p *Provider) key(ctx context.Context, kid string) (interface{}, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	age := time.Since(p.fetchedAt)
+	key, ok := p.keys[kid]
+	if ok && age < p.config.JWKSCacheTTL {
+		return key, nil
+	}
+	if ok || p.keys == nil || age >= p.config.JWKSRefreshInterval {
+		if err := p.refresh(ctx); err != nil {
+			if ok {
+				// Keep serving the cached key while the issuer is down.
+				return key, nil
+			}
+			return nil, fmt.Errorf("%w: %v", models.ErrIdentityProviderUnavailable, err)
+		}
+		if key, ok := p.keys[kid]; ok {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: unknown key ID %q", models.ErrInvalidIDToken, kid)
+}
+
+func (p *Provider) refresh(ctx context.Context) error {
+	if p.jwksURI == "" {
+		var doc discoveryDocument
+		wellKnown := strings.TrimSuffix(p.config.IssuerURL, "/") + "/.well-known/openid-configuration"
+		if err := p.getJSON(ctx, wellKnown, &doc); err != nil {
+			return err
+		}
+		if doc.Issuer != p.config.IssuerURL {
+			return fmt.Errorf("discovery document names issuer %q", doc.Issuer)
+		}
+		if doc.JWKSURI == "" {
+			return errors.New("discovery document has no jwks_uri")
+		}
+		p.jwksURI = doc.JWKSURI
+	}
+
+	var jwks models.JWKS
+	if err := p.getJSON(ctx, p.jwksURI, &jwks); err != nil {
+		return err
+	}
+	keys := map[string]interface{}{}
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := publicKey(jwk)
+		if err != nil {
+			continue
+		}
+		keys[jwk.KeyID] = key
+	}
+	p.keys = keys
+	p.fetchedAt = time.Now()
+	return nil
+}
+
+func (p *Provider) getJSON(ctx context.Context, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	res, err := p.config.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", url, res.Status)
+	}
+	return json.NewDecoder(res.Body).Decode(v)
+}
+
+func publicKey(jwk models.JWK) (interface{}, error) {
+	switch jwk.KeyType {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		exponent := new(big.Int).SetBytes(e)
+		if !exponent.IsInt64() || exponent.Int64() > 1<<31-1 {
+			return nil, errors.New("RSA exponent is too large")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+	case "EC":
+		if jwk.Curve != "P-256" {
+			return nil, fmt.Errorf("unsupported curve %q", jwk.Curve)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != 32 || len(y) != 32 {
+			return nil, errors.New("invalid P-256 coordinates")
+		}
+		point := append(append([]byte{4}, x...), y...)
+		return ecdsa.ParseUncompressedPublicKey(elliptic.P256(), point)
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", jwk.KeyType)
+	}
+}
+
+func stringClaim(claims jwt.MapClaims, name string) string {
+	value, _ := claims[name].(string)
+	return value
+}
+
+// stringsClaim reads a claim that is a list of strings, or a single string.
+func stringsClaim(claims jwt.MapClaims, name string) []string {
+	switch value := claims[name].(type) {
+	case string:
+		return []string{value}
+	case []interface{}:
+		values := []string{}
+		for _, v := range value {
+			if s, ok := v.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	default:
+		return nil
+	}
+}
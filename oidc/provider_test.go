@@ -0,0 +1,161 @@
+package oidc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gloonch/CarZone/models"
+	"github.com/gloonch/CarZone/oidc/oidctest"
+)
+
+const clientID = "carzone"
+
+func newTestProvider(t *testing.T, config Config) (*Provider, *oidctest.Issuer) {
+	t.Helper()
+
+	issuer, err := oidctest.NewIssuer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(issuer.Close)
+
+	config.IssuerURL = issuer.URL()
+	config.ClientID = clientID
+	provider, err := NewProvider(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return provider, issuer
+}
+
+func idToken(t *testing.T, issuer *oidctest.Issuer, audience string, extra map[string]interface{}) string {
+	t.Helper()
+
+	token, err := issuer.IDToken("subject-1", audience, extra)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestVerifyValidToken(t *testing.T) {
+	provider, issuer := newTestProvider(t, Config{})
+	token := idToken(t, issuer, clientID, map[string]interface{}{
+		"preferred_username": "jane",
+		"email":              "jane@corp.example",
+		"groups":             []string{"carzone-editors", "staff"},
+		"nonce":              "n-1",
+	})
+
+	identity, err := provider.Verify(context.Background(), token, "n-1")
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if identity.Subject != "subject-1" || identity.PreferredUsername != "jane" || identity.Email != "jane@corp.example" {
+		t.Fatalf("identity: got %+v", identity)
+	}
+	if len(identity.Groups) != 2 || identity.Groups[0] != "carzone-editors" || identity.Groups[1] != "staff" {
+		t.Fatalf("groups: got %v", identity.Groups)
+	}
+}
+
+func TestVerifyRejectsInvalidTokens(t *testing.T) {
+	provider, issuer := newTestProvider(t, Config{})
+	now := time.Now()
+
+	tests := []struct {
+		name     string
+		audience string
+		extra    map[string]interface{}
+		nonce    string
+	}{
+		{name: "wrong issuer", audience: clientID, extra: map[string]interface{}{"iss": "https://evil.example"}},
+		{name: "wrong audience", audience: "another-client"},
+		{name: "issued to another client", audience: clientID, extra: map[string]interface{}{"azp": "another-client"}},
+		{name: "expired", audience: clientID, extra: map[string]interface{}{
+			"iat": now.Add(-time.Hour).Unix(),
+			"exp": now.Add(-time.Minute).Unix(),
+		}},
+		{name: "issued in the future", audience: clientID, extra: map[string]interface{}{"iat": now.Add(time.Hour).Unix()}},
+		{name: "nonce mismatch", audience: clientID, extra: map[string]interface{}{"nonce": "n-1"}, nonce: "n-2"},
+		{name: "missing nonce", audience: clientID, nonce: "n-1"},
+		{name: "no subject", audience: clientID, extra: map[string]interface{}{"sub": ""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := provider.Verify(context.Background(), idToken(t, issuer, tt.audience, tt.extra), tt.nonce)
+			if !errors.Is(err, models.ErrInvalidIDToken) {
+				t.Fatalf("Verify: got %v, want %v", err, models.ErrInvalidIDToken)
+			}
+		})
+	}
+}
+
+func TestVerifyRejectsForgedSignature(t *testing.T) {
+	provider, issuer := newTestProvider(t, Config{})
+	other, err := oidctest.NewIssuer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer other.Close()
+
+	// Signed by another issuer's key under the same key ID, claiming to be
+	// from ours.
+	forged, err := other.IDToken("subject-1", clientID, map[string]interface{}{"iss": issuer.URL()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := provider.Verify(context.Background(), forged, ""); !errors.Is(err, models.ErrInvalidIDToken) {
+		t.Fatalf("Verify: got %v, want %v", err, models.ErrInvalidIDToken)
+	}
+}
+
+func TestVerifyFollowsKeyRotation(t *testing.T) {
+	provider, issuer := newTestProvider(t, Config{JWKSRefreshInterval: time.Nanosecond})
+	ctx := context.Background()
+
+	oldToken := idToken(t, issuer, clientID, nil)
+	if _, err := provider.Verify(ctx, oldToken, ""); err != nil {
+		t.Fatalf("Verify before rotation: %v", err)
+	}
+
+	if err := issuer.RotateKey(); err != nil {
+		t.Fatal(err)
+	}
+	newToken := idToken(t, issuer, clientID, nil)
+	if _, err := provider.Verify(ctx, newToken, ""); err != nil {
+		t.Fatalf("Verify of a token signed with the new key: %v", err)
+	}
+	if _, err := provider.Verify(ctx, oldToken, ""); err != nil {
+		t.Fatalf("Verify of a token signed with the previous key: %v", err)
+	}
+}
+
+func TestVerifyLimitsJWKSRefreshes(t *testing.T) {
+	provider, issuer := newTestProvider(t, Config{JWKSRefreshInterval: time.Hour})
+	ctx := context.Background()
+
+	if _, err := provider.Verify(ctx, idToken(t, issuer, clientID, nil), ""); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if err := issuer.RotateKey(); err != nil {
+		t.Fatal(err)
+	}
+	// The JWKS was fetched moments ago, so an unknown key is refused rather
+	// than refetched.
+	if _, err := provider.Verify(ctx, idToken(t, issuer, clientID, nil), ""); !errors.Is(err, models.ErrInvalidIDToken) {
+		t.Fatalf("Verify with an unknown key inside the refresh interval: got %v, want %v", err, models.ErrInvalidIDToken)
+	}
+}
+
+func TestVerifyReportsUnreachableIssuer(t *testing.T) {
+	provider, issuer := newTestProvider(t, Config{})
+	token := idToken(t, issuer, clientID, nil)
+	issuer.Close()
+
+	if _, err := provider.Verify(context.Background(), token, ""); !errors.Is(err, models.ErrIdentityProviderUnavailable) {
+		t.Fatalf("Verify: got %v, want %v", err, models.ErrIdentityProviderUnavailable)
+	}
+}
@@ -0,0 +1,92 @@
+// Package policy decides which records a user may modify beyond what their
+// role allows.
+package policy
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gloonch/CarZone/middleware"
+	"github.com/gloonch/CarZone/models"
+	"github.com/gloonch/CarZone/store"
+	"go.opentelemetry.io/otel"
+)
+
+var (
+	ErrNotCarOwner   = fmt.Errorf("%w: only the car's owner, editors at its location and admins may modify it", middleware.ErrForbidden)
+	ErrOtherLocation = fmt.Errorf("%w: editors may only list cars at their own location", middleware.ErrForbidden)
+)
+
+// CarPolicy lets admins modify every car of their tenant and editors only
+// the cars they own or that are listed at their location.
+type CarPolicy struct {
+	users store.UserStoreInterface
+}
+
+func NewCarPolicy(users store.UserStoreInterface) *CarPolicy {
+	return &CarPolicy{
+		users: users,
+	}
+}
+
+func (p *CarPolicy) CanModify(ctx context.Context, car models.Car) error {
+	tracer := otel.Tracer("car-policy")
+	ctx, span := tracer.Start(ctx, "CanModify-Policy")
+	defer span.End()
+
+	if middleware.IsAdmin(ctx) {
+		return nil
+	}
+	username, err := middleware.RequireUsername(ctx)
+	if err != nil {
+		return err
+	}
+	if car.CreatedBy == username {
+		return nil
+	}
+	location, err := p.location(ctx, username)
+	if err != nil {
+		return err
+	}
+	// Cars listed before locations were introduced have none and are left
+	// to their owners and admins.
+	if location == "" || location != car.Location {
+		return ErrNotCarOwner
+	}
+	return nil
+}
+
+// AssignLocation returns the location a car should be listed at when the
+// requesting user asks for location. Editors list at their own location,
+// which is also the default; admins may list anywhere.
+func (p *CarPolicy) AssignLocation(ctx context.Context, location string) (string, error) {
+	tracer := otel.Tracer("car-policy")
+	ctx, span := tracer.Start(ctx, "AssignLocation-Policy")
+	defer span.End()
+
+	if middleware.IsAdmin(ctx) && location != "" {
+		return location, nil
+	}
+	username, err := middleware.RequireUsername(ctx)
+	if err != nil {
+		return "", err
+	}
+	own, err := p.location(ctx, username)
+	if err != nil {
+		return "", err
+	}
+	if location != "" && location != own {
+		return "", ErrOtherLocation
+	}
+	return own, nil
+}
+
+// location looks the user's location up on every check, so reassigning an
+// editor takes effect immediately.
+func (p *CarPolicy) location(ctx context.Context, username string) (string, error) {
+	user, err := p.users.GetUserByUsername(ctx, username)
+	if err != nil {
+		return "", err
+	}
+	return user.Location, nil
+}
@@ -0,0 +1,163 @@
+package apikey
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gloonch/CarZone/middleware"
+	"github.com/gloonch/CarZone/models"
+	"github.com/gloonch/CarZone/store"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+)
+
+// keyPrefix marks CarZone API keys so leaked keys are easy to scan for.
+const keyPrefix = "cz_"
+
+type APIKeyService struct {
+	store store.APIKeyStoreInterface
+	users store.UserStoreInterface
+}
+
+func NewAPIKeyService(store store.APIKeyStoreInterface, users store.UserStoreInterface) *APIKeyService {
+	return &APIKeyService{
+		store: store,
+		users: users,
+	}
+}
+
+func (s *APIKeyService) GetAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	tracer := otel.Tracer("apikey-service")
+	ctx, span := tracer.Start(ctx, "GetAPIKeys-Service")
+	defer span.End()
+
+	if !middleware.IsAdmin(ctx) {
+		return nil, middleware.ErrForbidden
+	}
+	tenant, err := middleware.RequireTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.store.GetAPIKeys(ctx, tenant)
+}
+
+// CreateAPIKey issues a key for a user. The scopes may not grant more than
+// the owner's role. The key itself is only returned here.
+func (s *APIKeyService) CreateAPIKey(ctx context.Context, keyReq *models.APIKeyRequest) (*models.CreatedAPIKey, error) {
+	tracer := otel.Tracer("apikey-service")
+	ctx, span := tracer.Start(ctx, "CreateAPIKey-Service")
+	defer span.End()
+
+	if !middleware.IsAdmin(ctx) {
+		return nil, middleware.ErrForbidden
+	}
+
+	now := time.Now()
+	keyReq.Normalize()
+	if err := models.ValidateAPIKeyRequest(*keyReq, now); err != nil {
+		return nil, fmt.Errorf("%w: %v", models.ErrInvalidAPIKeyRequest, err)
+	}
+	tenant, err := middleware.RequireTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+	owner, err := s.users.GetUserByUsername(ctx, keyReq.Owner)
+	if err != nil {
+		return nil, err
+	}
+	if owner.TenantID != tenant {
+		return nil, models.ErrUserNotFound
+	}
+	if role := models.ScopeRole(keyReq.Scopes); !models.RoleAtLeast(owner.Role, role) {
+		return nil, fmt.Errorf("%w: %s is a %s and cannot hold keys with %s access",
+			models.ErrInvalidAPIKeyRequest, owner.Username, owner.Role, role)
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	id := uuid.New()
+	key := keyPrefix + hex.EncodeToString(id[:4]) + "_" + base64.RawURLEncoding.EncodeToString(secret)
+
+	createdKey, err := s.store.CreateAPIKey(ctx, models.APIKey{
+		ID:        id,
+		Name:      keyReq.Name,
+		Owner:     owner.Username,
+		Prefix:    key[:len(keyPrefix)+8],
+		KeyHash:   hashKey(key),
+		Scopes:    keyReq.Scopes,
+		ExpiresAt: keyReq.ExpiresAt,
+		CreatedBy: middleware.UsernameFromContext(ctx),
+		CreatedAt: now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &models.CreatedAPIKey{APIKey: createdKey, Key: key}, nil
+}
+
+func (s *APIKeyService) RevokeAPIKey(ctx context.Context, id string) (*models.APIKey, error) {
+	tracer := otel.Tracer("apikey-service")
+	ctx, span := tracer.Start(ctx, "RevokeAPIKey-Service")
+	defer span.End()
+
+	if !middleware.IsAdmin(ctx) {
+		return nil, middleware.ErrForbidden
+	}
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, models.ErrAPIKeyNotFound
+	}
+	tenant, err := middleware.RequireTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+	revokedKey, err := s.store.RevokeAPIKey(ctx, id, tenant, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return &revokedKey, nil
+}
+
+// AuthenticateAPIKey returns the principal a valid key acts as: its owner,
+// with the role of the key's scopes capped at the owner's current role.
+func (s *APIKeyService) AuthenticateAPIKey(ctx context.Context, key string) (*models.Principal, error) {
+	tracer := otel.Tracer("apikey-service")
+	ctx, span := tracer.Start(ctx, "AuthenticateAPIKey-Service")
+	defer span.End()
+
+	if !strings.HasPrefix(key, keyPrefix) {
+		return nil, models.ErrInvalidAPIKey
+	}
+	apiKey, err := s.store.UseAPIKey(ctx, hashKey(key), time.Now())
+	if err != nil {
+		return nil, err
+	}
+	owner, err := s.users.GetUserByUsername(ctx, apiKey.Owner)
+	if err != nil {
+		if errors.Is(err, models.ErrUserNotFound) {
+			return nil, models.ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+
+	role := models.ScopeRole(apiKey.Scopes)
+	if !models.RoleAtLeast(owner.Role, role) {
+		role = owner.Role
+	}
+	return &models.Principal{Username: owner.Username, Role: role, TenantID: owner.TenantID, APIKeyID: apiKey.ID.String()}, nil
+}
+
+// hashKey hashes an API key for storage. Keys carry 256 bits of entropy, so
+// a fast unsalted hash is enough.
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,43 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/gloonch/CarZone/middleware"
+	"github.com/gloonch/CarZone/models"
+	"github.com/gloonch/CarZone/store"
+	"go.opentelemetry.io/otel"
+)
+
+const (
+	defaultDenialLimit = 100
+	maxDenialLimit     = 1000
+)
+
+type AuditService struct {
+	store store.AuditStoreInterface
+}
+
+func NewAuditService(store store.AuditStoreInterface) *AuditService {
+	return &AuditService{
+		store: store,
+	}
+}
+
+// GetDenials lists recent access denials. Only admins may read the audit log.
+func (s *AuditService) GetDenials(ctx context.Context, username string, limit int) ([]models.AccessDenial, error) {
+	tracer := otel.Tracer("audit-service")
+	ctx, span := tracer.Start(ctx, "GetDenials-Service")
+	defer span.End()
+
+	if !middleware.IsAdmin(ctx) {
+		return nil, middleware.ErrForbidden
+	}
+	if limit <= 0 {
+		limit = defaultDenialLimit
+	}
+	if limit > maxDenialLimit {
+		limit = maxDenialLimit
+	}
+	return s.store.GetDenials(ctx, username, limit)
+}
@@ -2,23 +2,58 @@ package car
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sort"
 
+	"github.com/gloonch/CarZone/middleware"
 	"github.com/gloonch/CarZone/models"
+	"github.com/gloonch/CarZone/service"
 	"github.com/gloonch/CarZone/store"
 	"go.opentelemetry.io/otel"
 )
 
 type CarService struct {
-	store store.CarStoreInterface
+	store     store.CarStoreInterface
+	rates     service.ExchangeRateServiceInterface
+	favorites store.FavoriteStoreInterface
+	notifier  service.CarChangeNotifier
+	policy    service.CarPolicy
 }
 
-func NewCarService(store store.CarStoreInterface) *CarService {
+func NewCarService(store store.CarStoreInterface, rates service.ExchangeRateServiceInterface,
+	favorites store.FavoriteStoreInterface, notifier service.CarChangeNotifier, policy service.CarPolicy) *CarService {
 	return &CarService{
-		store: store,
+		store:     store,
+		rates:     rates,
+		favorites: favorites,
+		notifier:  notifier,
+		policy:    policy,
 	}
 }
 
-func (s *CarService) GetCarByID(ctx context.Context, id string) (*models.Car, error) {
+// markFavorites sets IsFavorite on the cars the requesting user has starred.
+func (s *CarService) markFavorites(ctx context.Context, cars []*models.Car) error {
+	username := middleware.UsernameFromContext(ctx)
+	if username == "" || len(cars) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(cars))
+	for i, car := range cars {
+		ids[i] = car.ID.String()
+	}
+	favorites, err := s.favorites.FavoriteCarIDs(ctx, username, ids)
+	if err != nil {
+		return err
+	}
+	for _, car := range cars {
+		car.IsFavorite = favorites[car.ID]
+	}
+	return nil
+}
+
+func (s *CarService) GetCarByID(ctx context.Context, id string, currency string) (*models.Car, error) {
 	tracer := otel.Tracer("car-service")
 	ctx, span := tracer.Start(ctx, "GetCarByID-Service")
 	defer span.End()
@@ -27,58 +62,258 @@ func (s *CarService) GetCarByID(ctx context.Context, id string) (*models.Car, er
 	if err != nil {
 		return nil, err
 	}
+	car.ConvertedPrice, err = s.rates.ConvertPrice(ctx, car.Price, currency)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.markFavorites(ctx, []*models.Car{&car}); err != nil {
+		return nil, err
+	}
 	return &car, nil
 }
 
-func (s *CarService) GetCarsByBrand(ctx context.Context, brand string, isEngine bool) ([]models.Car, error) {
+func (s *CarService) GetCarsByBrand(ctx context.Context, query models.CarQuery) ([]models.Car, error) {
 	tracer := otel.Tracer("car-service")
 	ctx, span := tracer.Start(ctx, "GetCarsByBrand-Service")
 	defer span.End()
 
-	cars, err := s.store.GetCarByBrand(ctx, brand, isEngine)
+	query.Tags = models.NormalizeTags(query.Tags)
+	if err := models.ValidateCarQuery(query); err != nil {
+		return nil, err
+	}
+
+	cars, err := s.store.GetCarByBrand(ctx, query)
 	if err != nil {
 		return nil, err
 	}
+	refs := make([]*models.Car, len(cars))
+	for i := range cars {
+		cars[i].ConvertedPrice, err = s.rates.ConvertPrice(ctx, cars[i].Price, query.Currency)
+		if err != nil {
+			return nil, err
+		}
+		refs[i] = &cars[i]
+	}
+	if err := s.markFavorites(ctx, refs); err != nil {
+		return nil, err
+	}
+	sortCars(cars, query.Sort)
 	return cars, nil
 }
 
+// sortCars orders a listing by the requested sort option, best first.
+func sortCars(cars []models.Car, sortBy string) {
+	switch sortBy {
+	case models.CarSortRating:
+		sort.SliceStable(cars, func(i, j int) bool {
+			if cars[i].Rating.Average != cars[j].Rating.Average {
+				return cars[i].Rating.Average > cars[j].Rating.Average
+			}
+			return cars[i].Rating.Count > cars[j].Rating.Count
+		})
+	case models.CarSortReviews:
+		sort.SliceStable(cars, func(i, j int) bool {
+			return cars[i].Rating.Count > cars[j].Rating.Count
+		})
+	}
+}
+
+// CompareCars loads the requested cars in one round-trip and aligns them into
+// an attribute matrix, keeping the order of ids.
+func (s *CarService) CompareCars(ctx context.Context, ids []string, currency string) (*models.CarComparison, error) {
+	tracer := otel.Tracer("car-service")
+	ctx, span := tracer.Start(ctx, "CompareCars-Service")
+	defer span.End()
+
+	if err := models.ValidateComparisonIDs(ids); err != nil {
+		return nil, err
+	}
+	ids = models.CanonicalCarIDs(ids)
+
+	found, err := s.store.GetCarsByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]models.Car, len(found))
+	for _, car := range found {
+		byID[car.ID.String()] = car
+	}
+
+	cars := make([]models.Car, 0, len(ids))
+	for _, id := range ids {
+		car, ok := byID[id]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", models.ErrCarNotFound, id)
+		}
+		car.ConvertedPrice, err = s.rates.ConvertPrice(ctx, car.Price, currency)
+		if err != nil {
+			return nil, err
+		}
+		cars = append(cars, car)
+	}
+	refs := make([]*models.Car, len(cars))
+	for i := range cars {
+		refs[i] = &cars[i]
+	}
+	if err := s.markFavorites(ctx, refs); err != nil {
+		return nil, err
+	}
+
+	comparison := buildComparison(cars)
+	return &comparison, nil
+}
+
+// SimilarCars ranks every other car by weighted similarity to the car with
+// the given id and returns the best matches with their media. Cars carry no
+// sale status, so every listed car is treated as available.
+func (s *CarService) SimilarCars(ctx context.Context, id string, weights models.SimilarityWeights, limit int) ([]models.SimilarCar, error) {
+	tracer := otel.Tracer("car-service")
+	ctx, span := tracer.Start(ctx, "SimilarCars-Service")
+	defer span.End()
+
+	if err := models.ValidateSimilarityWeights(weights); err != nil {
+		return nil, err
+	}
+	if err := models.ValidateSimilarLimit(limit); err != nil {
+		return nil, err
+	}
+
+	target, err := s.store.GetCarByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	candidates, err := s.store.GetAllCars(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	similar := rankSimilar(target, candidates, weights, limit)
+	if len(similar) == 0 {
+		return similar, nil
+	}
+
+	ids := make([]string, len(similar))
+	for i, match := range similar {
+		ids[i] = match.Car.ID.String()
+	}
+	withMedia, err := s.store.GetCarsByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	refs := make([]*models.Car, len(similar))
+	for i := range similar {
+		for _, car := range withMedia {
+			if similar[i].Car.ID == car.ID {
+				similar[i].Car = car
+			}
+		}
+		refs[i] = &similar[i].Car
+	}
+	if err := s.markFavorites(ctx, refs); err != nil {
+		return nil, err
+	}
+	return similar, nil
+}
+
 func (s *CarService) CreateCar(ctx context.Context, carReq *models.CarRequest) (*models.Car, error) {
 	tracer := otel.Tracer("car-service")
 	ctx, span := tracer.Start(ctx, "CreateCar-Service")
 	defer span.End()
 
+	carReq.Normalize()
 	if err := models.ValidateCarRequest(*carReq); err != nil {
 		return nil, err
 	}
+	if carReq.OdometerOverride != nil {
+		return nil, errors.New("odometerOverride only applies to updates")
+	}
+	location, err := s.policy.AssignLocation(ctx, carReq.Location)
+	if err != nil {
+		return nil, err
+	}
+	carReq.Location = location
 
-	createdCar, err := s.store.CreateCar(ctx, carReq)
+	createdCar, err := s.store.CreateCar(ctx, carReq, middleware.UsernameFromContext(ctx))
 	if err != nil {
 		return nil, err
 	}
+	s.notifier.NotifyCarChanged(ctx, createdCar, models.CarEventCreated)
 	return &createdCar, nil
 }
 
+// UpdateCar replaces a car's details. Editors may only update cars they own
+// or that are listed at their location.
 func (s *CarService) UpdateCar(ctx context.Context, id string, carReq *models.CarRequest) (*models.Car, error) {
 	tracer := otel.Tracer("car-service")
 	ctx, span := tracer.Start(ctx, "UpdateCar-Service")
 	defer span.End()
 
+	carReq.Normalize()
 	if err := models.ValidateCarRequest(*carReq); err != nil {
 		return nil, err
 	}
-	updatedCar, err := s.store.UpdateCar(ctx, id, carReq)
+	if carReq.OdometerOverride != nil && !middleware.IsAdmin(ctx) {
+		return nil, middleware.ErrForbidden
+	}
+	currentCar, err := s.store.GetCarByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
+	if err := s.policy.CanModify(ctx, currentCar); err != nil {
+		return nil, err
+	}
+	if carReq.Location == "" {
+		carReq.Location = currentCar.Location
+	} else if carReq.Location != currentCar.Location {
+		carReq.Location, err = s.policy.AssignLocation(ctx, carReq.Location)
+		if err != nil {
+			return nil, err
+		}
+	}
+	updatedCar, err := s.store.UpdateCar(ctx, id, carReq, middleware.UsernameFromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	s.notifier.NotifyCarChanged(ctx, updatedCar, models.CarEventUpdated)
 	return &updatedCar, nil
 }
 
+// GetOdometerAudit lists the overridden odometer corrections of a car.
+func (s *CarService) GetOdometerAudit(ctx context.Context, id string) ([]models.OdometerAudit, error) {
+	tracer := otel.Tracer("car-service")
+	ctx, span := tracer.Start(ctx, "GetOdometerAudit-Service")
+	defer span.End()
+
+	if !middleware.IsAdmin(ctx) {
+		return nil, middleware.ErrForbidden
+	}
+	return s.store.GetOdometerAudit(ctx, id)
+}
+
+func (s *CarService) GetTags(ctx context.Context) ([]models.Tag, error) {
+	tracer := otel.Tracer("car-service")
+	ctx, span := tracer.Start(ctx, "GetTags-Service")
+	defer span.End()
+
+	return s.store.GetTags(ctx)
+}
+
+// DeleteCar removes a car. Editors may only delete cars they own or that are
+// listed at their location.
 func (s *CarService) DeleteCar(ctx context.Context, id string) (*models.Car, error) {
 
 	tracer := otel.Tracer("car-service")
 	ctx, span := tracer.Start(ctx, "DeleteCar-Service")
 	defer span.End()
 
+	currentCar, err := s.store.GetCarByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.policy.CanModify(ctx, currentCar); err != nil {
+		return nil, err
+	}
+
 	deletedCar, err := s.store.DeleteCar(ctx, id)
 	if err != nil {
 		return nil, err
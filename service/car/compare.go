@@ -0,0 +1,181 @@
+package car
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/gloonch/CarZone/models"
+	"github.com/google/uuid"
+)
+
+const (
+	noRanking    = 0
+	higherBetter = 1
+	lowerBetter  = -1
+)
+
+type comparedAttribute struct {
+	name   string
+	better int
+	value  func(car models.Car) interface{}
+	number func(car models.Car) (float64, bool)
+}
+
+var comparedAttributes = []comparedAttribute{
+	{name: "name", value: func(car models.Car) interface{} { return car.Name }},
+	{name: "brand", value: func(car models.Car) interface{} { return car.Brand }},
+	{
+		name:   "year",
+		better: higherBetter,
+		value:  func(car models.Car) interface{} { return car.Year },
+		number: func(car models.Car) (float64, bool) {
+			year, err := strconv.Atoi(car.Year)
+			return float64(year), err == nil
+		},
+	},
+	{name: "fuelType", value: func(car models.Car) interface{} { return car.FuelType }},
+	{
+		name:   "price",
+		better: lowerBetter,
+		value:  func(car models.Car) interface{} { return comparedPrice(car) },
+		number: func(car models.Car) (float64, bool) { return comparedPrice(car).Amount.Float64(), true },
+	},
+	{
+		name:   "mileage",
+		better: lowerBetter,
+		value:  func(car models.Car) interface{} { return car.Mileage },
+		number: func(car models.Car) (float64, bool) { return float64(car.Mileage), true },
+	},
+	{name: "condition", value: func(car models.Car) interface{} { return car.Condition }},
+	{
+		name:   "previousOwners",
+		better: lowerBetter,
+		value:  func(car models.Car) interface{} { return car.PreviousOwners },
+		number: func(car models.Car) (float64, bool) { return float64(car.PreviousOwners), true },
+	},
+	{name: "engine.engineType", value: func(car models.Car) interface{} { return car.Engine.EngineType }},
+	{
+		name:   "engine.displacement",
+		value:  func(car models.Car) interface{} { return car.Engine.Displacement },
+		number: func(car models.Car) (float64, bool) { return float64(car.Engine.Displacement), true },
+	},
+	{
+		name:   "engine.noOfCylinders",
+		value:  func(car models.Car) interface{} { return car.Engine.NoOfCylinders },
+		number: func(car models.Car) (float64, bool) { return float64(car.Engine.NoOfCylinders), true },
+	},
+	{
+		name:   "engine.carRange",
+		better: higherBetter,
+		value:  func(car models.Car) interface{} { return car.Engine.CarRange },
+		number: func(car models.Car) (float64, bool) { return float64(car.Engine.CarRange), true },
+	},
+	floatAttribute("engine.powerKw", higherBetter, func(car models.Car) *float64 { return car.Engine.PowerKW }),
+	floatAttribute("engine.powerHp", higherBetter, func(car models.Car) *float64 { return car.Engine.PowerHP }),
+	floatAttribute("engine.torqueNm", higherBetter, func(car models.Car) *float64 { return car.Engine.TorqueNm }),
+	stringAttribute("engine.aspiration", func(car models.Car) *string { return car.Engine.Aspiration }),
+	stringAttribute("engine.transmission", func(car models.Car) *string { return car.Engine.Transmission }),
+	floatAttribute("engine.batteryCapacityKwh", higherBetter, func(car models.Car) *float64 { return car.Engine.BatteryCapacityKWh }),
+	floatAttribute("engine.chargingPowerKw", higherBetter, func(car models.Car) *float64 { return car.Engine.ChargingPowerKW }),
+}
+
+func floatAttribute(name string, better int, field func(car models.Car) *float64) comparedAttribute {
+	return comparedAttribute{
+		name:   name,
+		better: better,
+		value: func(car models.Car) interface{} {
+			if v := field(car); v != nil {
+				return *v
+			}
+			return nil
+		},
+		number: func(car models.Car) (float64, bool) {
+			if v := field(car); v != nil {
+				return *v, true
+			}
+			return 0, false
+		},
+	}
+}
+
+func stringAttribute(name string, field func(car models.Car) *string) comparedAttribute {
+	return comparedAttribute{
+		name: name,
+		value: func(car models.Car) interface{} {
+			if v := field(car); v != nil {
+				return *v
+			}
+			return nil
+		},
+	}
+}
+
+// comparedPrice is the price used for comparison: the converted price when
+// the caller asked for a currency, the list price otherwise.
+func comparedPrice(car models.Car) models.Money {
+	if car.ConvertedPrice != nil {
+		return car.ConvertedPrice.Price
+	}
+	return car.Price
+}
+
+func buildComparison(cars []models.Car) models.CarComparison {
+	comparison := models.CarComparison{Cars: cars}
+
+	samePriceCurrency := true
+	for _, car := range cars {
+		if comparedPrice(car).Currency != comparedPrice(cars[0]).Currency {
+			samePriceCurrency = false
+		}
+	}
+
+	for _, attr := range comparedAttributes {
+		row := models.ComparisonAttribute{Name: attr.name, Values: make([]interface{}, len(cars))}
+		for i, car := range cars {
+			row.Values[i] = attr.value(car)
+			if fmt.Sprint(row.Values[i]) != fmt.Sprint(row.Values[0]) {
+				row.Differs = true
+			}
+		}
+
+		ranked := row.Differs && attr.better != noRanking && attr.number != nil
+		if attr.name == "price" && !samePriceCurrency {
+			ranked = false
+		}
+		if ranked {
+			row.Best, row.Worst = rankCars(cars, attr)
+		}
+		comparison.Attributes = append(comparison.Attributes, row)
+	}
+	return comparison
+}
+
+// rankCars returns the IDs of the cars with the best and worst value for the
+// attribute. Ties are all reported; cars without a value are skipped.
+func rankCars(cars []models.Car, attr comparedAttribute) ([]uuid.UUID, []uuid.UUID) {
+	var best, worst []uuid.UUID
+	var bestValue, worstValue float64
+	for _, car := range cars {
+		value, ok := attr.number(car)
+		if !ok {
+			continue
+		}
+		score := value * float64(attr.better)
+		switch {
+		case best == nil || score > bestValue:
+			best, bestValue = []uuid.UUID{car.ID}, score
+		case score == bestValue:
+			best = append(best, car.ID)
+		}
+		switch {
+		case worst == nil || score < worstValue:
+			worst, worstValue = []uuid.UUID{car.ID}, score
+		case score == worstValue:
+			worst = append(worst, car.ID)
+		}
+	}
+	if bestValue == worstValue {
+		return nil, nil
+	}
+	return best, worst
+}
@@ -0,0 +1,104 @@
+package car
+
+import (
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/gloonch/CarZone/models"
+)
+
+// yearSpan is the age difference at which two cars stop being similar by year.
+const yearSpan = 10
+
+// similarity scores candidate against target between 0 and 1, returning the
+// per-aspect scores alongside the weighted total.
+func similarity(target, candidate models.Car, weights models.SimilarityWeights) (float64, map[string]float64) {
+	breakdown := map[string]float64{
+		"price":    priceSimilarity(target.Price, candidate.Price),
+		"fuelType": equalSimilarity(target.FuelType, candidate.FuelType),
+		"brand":    equalSimilarity(target.Brand, candidate.Brand),
+		"year":     yearSimilarity(target.Year, candidate.Year),
+		"engine":   engineSimilarity(target.Engine, candidate.Engine),
+	}
+
+	total := weights.Price + weights.FuelType + weights.Brand + weights.Year + weights.Engine
+	score := (weights.Price*breakdown["price"] +
+		weights.FuelType*breakdown["fuelType"] +
+		weights.Brand*breakdown["brand"] +
+		weights.Year*breakdown["year"] +
+		weights.Engine*breakdown["engine"]) / total
+	return round(score), breakdown
+}
+
+// rankSimilar orders candidates by descending score, breaking ties by name so
+// results are stable.
+func rankSimilar(target models.Car, candidates []models.Car, weights models.SimilarityWeights, limit int) []models.SimilarCar {
+	var similar []models.SimilarCar
+	for _, candidate := range candidates {
+		if candidate.ID == target.ID {
+			continue
+		}
+		score, breakdown := similarity(target, candidate, weights)
+		for aspect, value := range breakdown {
+			breakdown[aspect] = round(value)
+		}
+		similar = append(similar, models.SimilarCar{Car: candidate, Score: score, Breakdown: breakdown})
+	}
+
+	sort.SliceStable(similar, func(i, j int) bool {
+		if similar[i].Score != similar[j].Score {
+			return similar[i].Score > similar[j].Score
+		}
+		return similar[i].Car.Name < similar[j].Car.Name
+	})
+	if len(similar) > limit {
+		similar = similar[:limit]
+	}
+	return similar
+}
+
+// priceSimilarity compares prices relative to the larger one, so cars within
+// the same price band score close to 1. Prices in different currencies are
+// not comparable without a rate and score 0.
+func priceSimilarity(a, b models.Money) float64 {
+	if a.Currency != b.Currency {
+		return 0
+	}
+	return ratioSimilarity(a.Amount.Float64(), b.Amount.Float64())
+}
+
+func equalSimilarity(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+	return 0
+}
+
+func yearSimilarity(a, b string) float64 {
+	yearA, errA := strconv.Atoi(a)
+	yearB, errB := strconv.Atoi(b)
+	if errA != nil || errB != nil {
+		return 0
+	}
+	diff := math.Abs(float64(yearA - yearB))
+	return math.Max(0, 1-diff/yearSpan)
+}
+
+func engineSimilarity(a, b models.Engine) float64 {
+	return (ratioSimilarity(float64(a.Displacement), float64(b.Displacement)) +
+		ratioSimilarity(float64(a.NoOfCylinders), float64(b.NoOfCylinders)) +
+		ratioSimilarity(float64(a.CarRange), float64(b.CarRange))) / 3
+}
+
+func ratioSimilarity(a, b float64) float64 {
+	largest := math.Max(math.Abs(a), math.Abs(b))
+	if largest == 0 {
+		return 1
+	}
+	return math.Max(0, 1-math.Abs(a-b)/largest)
+}
+
+func round(value float64) float64 {
+	return math.Round(value*1000) / 1000
+}
@@ -38,6 +38,7 @@ func (e *EngineService) CreateEngine(ctx context.Context, engineReq *models.Engi
 	if err := models.ValidateEngineRequest(*engineReq); err != nil {
 		return nil, err
 	}
+	engineReq.Normalize()
 
 	createdEngine, err := e.store.CreateEngine(ctx, engineReq)
 	if err != nil {
@@ -55,6 +56,7 @@ func (e *EngineService) UpdateEngine(ctx context.Context, id string, engineReq *
 	if err := models.ValidateEngineRequest(*engineReq); err != nil {
 		return nil, err
 	}
+	engineReq.Normalize()
 	updatedEngine, err := e.store.UpdateEngine(ctx, id, engineReq)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,81 @@
+package exchangerate
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gloonch/CarZone/models"
+	"github.com/gloonch/CarZone/store"
+	"go.opentelemetry.io/otel"
+)
+
+type ExchangeRateService struct {
+	store store.ExchangeRateStoreInterface
+}
+
+func NewExchangeRateService(store store.ExchangeRateStoreInterface) *ExchangeRateService {
+	return &ExchangeRateService{
+		store: store,
+	}
+}
+
+func (s *ExchangeRateService) GetExchangeRates(ctx context.Context) ([]models.ExchangeRate, error) {
+	tracer := otel.Tracer("exchange-rate-service")
+	ctx, span := tracer.Start(ctx, "GetExchangeRates-Service")
+	defer span.End()
+
+	return s.store.GetExchangeRates(ctx)
+}
+
+func (s *ExchangeRateService) CreateExchangeRate(ctx context.Context, rateReq *models.ExchangeRateRequest) (*models.ExchangeRate, error) {
+	tracer := otel.Tracer("exchange-rate-service")
+	ctx, span := tracer.Start(ctx, "CreateExchangeRate-Service")
+	defer span.End()
+
+	rateReq.BaseCurrency = strings.ToUpper(rateReq.BaseCurrency)
+	rateReq.QuoteCurrency = strings.ToUpper(rateReq.QuoteCurrency)
+	if err := models.ValidateExchangeRateRequest(*rateReq); err != nil {
+		return nil, err
+	}
+
+	createdRate, err := s.store.CreateExchangeRate(ctx, rateReq)
+	if err != nil {
+		return nil, err
+	}
+	return &createdRate, nil
+}
+
+func (s *ExchangeRateService) DeleteExchangeRate(ctx context.Context, id string) (*models.ExchangeRate, error) {
+	tracer := otel.Tracer("exchange-rate-service")
+	ctx, span := tracer.Start(ctx, "DeleteExchangeRate-Service")
+	defer span.End()
+
+	deletedRate, err := s.store.DeleteExchangeRate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &deletedRate, nil
+}
+
+// ConvertPrice converts price into currency using the latest stored rate. It
+// returns nil when no conversion is needed.
+func (s *ExchangeRateService) ConvertPrice(ctx context.Context, price models.Money, currency string) (*models.ConvertedPrice, error) {
+	tracer := otel.Tracer("exchange-rate-service")
+	ctx, span := tracer.Start(ctx, "ConvertPrice-Service")
+	defer span.End()
+
+	currency = strings.ToUpper(currency)
+	if currency == "" || currency == price.Currency {
+		return nil, nil
+	}
+	if err := models.ValidateCurrency(currency); err != nil {
+		return nil, err
+	}
+
+	rate, err := s.store.LatestExchangeRate(ctx, price.Currency, currency)
+	if err != nil {
+		return nil, err
+	}
+	converted := rate.Convert(price)
+	return &converted, nil
+}
@@ -0,0 +1,93 @@
+package favorite
+
+import (
+	"context"
+
+	"github.com/gloonch/CarZone/middleware"
+	"github.com/gloonch/CarZone/models"
+	"github.com/gloonch/CarZone/store"
+	"go.opentelemetry.io/otel"
+)
+
+type FavoriteService struct {
+	store    store.FavoriteStoreInterface
+	carStore store.CarStoreInterface
+}
+
+func NewFavoriteService(store store.FavoriteStoreInterface, carStore store.CarStoreInterface) *FavoriteService {
+	return &FavoriteService{
+		store:    store,
+		carStore: carStore,
+	}
+}
+
+func (s *FavoriteService) GetFavorites(ctx context.Context) ([]models.Favorite, error) {
+	tracer := otel.Tracer("favorite-service")
+	ctx, span := tracer.Start(ctx, "GetFavorites-Service")
+	defer span.End()
+
+	username, err := middleware.RequireUsername(ctx)
+	if err != nil {
+		return nil, err
+	}
+	favorites, err := s.store.GetFavorites(ctx, username)
+	if err != nil || len(favorites) == 0 {
+		return favorites, err
+	}
+
+	ids := make([]string, len(favorites))
+	for i, favorite := range favorites {
+		ids[i] = favorite.Car.ID.String()
+	}
+	cars, err := s.carStore.GetCarsByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, car := range cars {
+		car.IsFavorite = true
+		for i := range favorites {
+			if favorites[i].Car.ID == car.ID {
+				favorites[i].Car = car
+			}
+		}
+	}
+	return favorites, nil
+}
+
+func (s *FavoriteService) AddFavorite(ctx context.Context, favoriteReq *models.FavoriteRequest) (*models.Favorite, error) {
+	tracer := otel.Tracer("favorite-service")
+	ctx, span := tracer.Start(ctx, "AddFavorite-Service")
+	defer span.End()
+
+	username, err := middleware.RequireUsername(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := models.ValidateFavoriteRequest(*favoriteReq); err != nil {
+		return nil, err
+	}
+
+	// Look the car up first so that a car of another tenant is never starred.
+	car, err := s.carStore.GetCarByID(ctx, favoriteReq.CarID.String())
+	if err != nil {
+		return nil, err
+	}
+	createdAt, err := s.store.AddFavorite(ctx, username, favoriteReq.CarID)
+	if err != nil {
+		return nil, err
+	}
+	car.IsFavorite = true
+	return &models.Favorite{Car: car, CreatedAt: createdAt}, nil
+}
+
+func (s *FavoriteService) RemoveFavorite(ctx context.Context, carID string) error {
+	tracer := otel.Tracer("favorite-service")
+	ctx, span := tracer.Start(ctx, "RemoveFavorite-Service")
+	defer span.End()
+
+	username, err := middleware.RequireUsername(ctx)
+	if err != nil {
+		return err
+	}
+	return s.store.RemoveFavorite(ctx, username, carID)
+}
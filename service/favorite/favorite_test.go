@@ -0,0 +1,58 @@
+package favorite
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gloonch/CarZone/models"
+	"github.com/gloonch/CarZone/store"
+	"github.com/google/uuid"
+)
+
+// tenantCarStore only knows the cars of the caller's tenant, like the real
+// store does.
+type tenantCarStore struct {
+	store.CarStoreInterface
+	cars map[string]models.Car
+}
+
+func (s tenantCarStore) GetCarByID(ctx context.Context, id string) (models.Car, error) {
+	car, ok := s.cars[id]
+	if !ok {
+		return car, models.ErrCarNotFound
+	}
+	return car, nil
+}
+
+type recordingFavoriteStore struct {
+	store.FavoriteStoreInterface
+	added []uuid.UUID
+}
+
+func (s *recordingFavoriteStore) AddFavorite(ctx context.Context, username string, carID uuid.UUID) (time.Time, error) {
+	s.added = append(s.added, carID)
+	return time.Now(), nil
+}
+
+func TestAddFavoriteOfOtherTenantsCarIsNotStored(t *testing.T) {
+	ownCar := models.Car{ID: uuid.New()}
+	favorites := &recordingFavoriteStore{}
+	service := NewFavoriteService(favorites, tenantCarStore{cars: map[string]models.Car{ownCar.ID.String(): ownCar}})
+	ctx := models.WithTenant(context.WithValue(context.Background(), "username", "alice"), "tenant-a")
+
+	if _, err := service.AddFavorite(ctx, &models.FavoriteRequest{CarID: uuid.New()}); err == nil {
+		t.Fatal("starring a car outside the tenant succeeded")
+	}
+	if len(favorites.added) != 0 {
+		t.Fatalf("a favourite was stored for a car outside the tenant: %v", favorites.added)
+	}
+
+	favorite, err := service.AddFavorite(ctx, &models.FavoriteRequest{CarID: ownCar.ID})
+	if err != nil {
+		t.Fatalf("starring a car of the tenant: %v", err)
+	}
+	if !favorite.Car.IsFavorite || len(favorites.added) != 1 || favorites.added[0] != ownCar.ID {
+		t.Fatalf("starring a car of the tenant: got %+v, stored %v", favorite, favorites.added)
+	}
+}
@@ -2,14 +2,20 @@ package service
 
 import (
 	"context"
+	"io"
+	"time"
 
 	"github.com/gloonch/CarZone/models"
 )
 
 type CarServiceInterface interface {
-	GetCarByID(ctx context.Context, id string) (*models.Car, error)
-	GetCarsByBrand(ctx context.Context, brand string, isEngine bool) ([]models.Car, error)
+	GetCarByID(ctx context.Context, id string, currency string) (*models.Car, error)
+	GetCarsByBrand(ctx context.Context, query models.CarQuery) ([]models.Car, error)
+	CompareCars(ctx context.Context, ids []string, currency string) (*models.CarComparison, error)
+	SimilarCars(ctx context.Context, id string, weights models.SimilarityWeights, limit int) ([]models.SimilarCar, error)
 	UpdateCar(ctx context.Context, id string, carReq *models.CarRequest) (*models.Car, error)
+	GetOdometerAudit(ctx context.Context, id string) ([]models.OdometerAudit, error)
+	GetTags(ctx context.Context) ([]models.Tag, error)
 	CreateCar(ctx context.Context, carReq *models.CarRequest) (*models.Car, error)
 	DeleteCar(ctx context.Context, id string) (*models.Car, error)
 }
@@ -20,3 +26,120 @@ type EngineServiceInterface interface {
 	UpdateEngine(ctx context.Context, id string, engineReq *models.EngineRequest) (*models.Engine, error)
 	DeleteEngine(ctx context.Context, id string) (*models.Engine, error)
 }
+
+type ExchangeRateServiceInterface interface {
+	GetExchangeRates(ctx context.Context) ([]models.ExchangeRate, error)
+	CreateExchangeRate(ctx context.Context, rateReq *models.ExchangeRateRequest) (*models.ExchangeRate, error)
+	DeleteExchangeRate(ctx context.Context, id string) (*models.ExchangeRate, error)
+	ConvertPrice(ctx context.Context, price models.Money, currency string) (*models.ConvertedPrice, error)
+}
+
+type MediaServiceInterface interface {
+	GetMediaByCar(ctx context.Context, carID string) ([]models.CarMedia, error)
+	UploadMedia(ctx context.Context, carID string, fileName string, data []byte) (*models.CarMedia, error)
+	OpenMedia(ctx context.Context, carID string, mediaID string, thumbnail bool) (*models.CarMedia, io.ReadCloser, error)
+	DeleteMedia(ctx context.Context, carID string, mediaID string) (*models.CarMedia, error)
+}
+
+type FavoriteServiceInterface interface {
+	GetFavorites(ctx context.Context) ([]models.Favorite, error)
+	AddFavorite(ctx context.Context, favoriteReq *models.FavoriteRequest) (*models.Favorite, error)
+	RemoveFavorite(ctx context.Context, carID string) error
+}
+
+type SavedSearchServiceInterface interface {
+	GetSavedSearches(ctx context.Context) ([]models.SavedSearch, error)
+	CreateSavedSearch(ctx context.Context, searchReq *models.SavedSearchRequest) (*models.SavedSearch, error)
+	DeleteSavedSearch(ctx context.Context, id string) (*models.SavedSearch, error)
+	GetNotifications(ctx context.Context, unreadOnly bool) ([]models.Notification, error)
+	MarkNotificationRead(ctx context.Context, id string) error
+}
+
+// CarChangeNotifier is told about every car CarService creates or updates,
+// with the context of the request that changed it.
+type CarChangeNotifier interface {
+	NotifyCarChanged(ctx context.Context, car models.Car, event string)
+}
+
+// CarPolicy decides which cars the requesting user may modify and where they
+// may list them; *policy.CarPolicy implements it.
+type CarPolicy interface {
+	CanModify(ctx context.Context, car models.Car) error
+	AssignLocation(ctx context.Context, location string) (string, error)
+}
+
+type ReviewServiceInterface interface {
+	GetCarReviews(ctx context.Context, carID string) ([]models.Review, error)
+	GetReviews(ctx context.Context, status string) ([]models.Review, error)
+	CreateReview(ctx context.Context, reviewReq *models.ReviewRequest) (*models.Review, error)
+	ModerateReview(ctx context.Context, id string, moderationReq *models.ModerationRequest) (*models.Review, error)
+	DeleteReview(ctx context.Context, id string) (*models.Review, error)
+}
+
+type ServiceRecordServiceInterface interface {
+	GetServiceRecords(ctx context.Context, carID string) ([]models.ServiceRecord, error)
+	CreateServiceRecord(ctx context.Context, carID string, recordReq *models.ServiceRecordRequest) (*models.ServiceRecord, error)
+	DeleteServiceRecord(ctx context.Context, carID string, id string) (*models.ServiceRecord, error)
+	GetServiceDue(ctx context.Context, maxDays int, maxDistance int64) ([]models.ServiceDue, error)
+}
+
+type ValuationServiceInterface interface {
+	ValuateCar(ctx context.Context, carID string, currency string) (*models.Valuation, error)
+	GetCurves(ctx context.Context) ([]models.DepreciationCurve, error)
+	SaveCurve(ctx context.Context, brand string, curveReq *models.DepreciationCurveRequest) (*models.DepreciationCurve, error)
+	DeleteCurve(ctx context.Context, brand string) (*models.DepreciationCurve, error)
+}
+
+type QuoteServiceInterface interface {
+	CreateQuote(ctx context.Context, carID string, quoteReq *models.QuoteRequest) (*models.Quote, error)
+	GetQuote(ctx context.Context, id string) (*models.Quote, error)
+	GetMyQuotes(ctx context.Context) ([]models.Quote, error)
+}
+
+type OptionServiceInterface interface {
+	GetPackagesForCar(ctx context.Context, carID string) ([]models.OptionPackage, error)
+	CreatePackage(ctx context.Context, packageReq *models.OptionPackageRequest) (*models.OptionPackage, error)
+	DeletePackage(ctx context.Context, id string) (*models.OptionPackage, error)
+	Configure(ctx context.Context, carID string, configReq *models.ConfigurationRequest, currency string) (*models.Configuration, error)
+}
+
+type UserServiceInterface interface {
+	Authenticate(ctx context.Context, credentials models.Credentials) (*models.User, error)
+	GetUsers(ctx context.Context) ([]models.User, error)
+	CreateUser(ctx context.Context, userReq *models.UserRequest) (*models.User, error)
+	EnsureAdmin(ctx context.Context, username string, password string) error
+}
+
+type AuditServiceInterface interface {
+	GetDenials(ctx context.Context, username string, limit int) ([]models.AccessDenial, error)
+}
+
+type PasswordServiceInterface interface {
+	ChangePassword(ctx context.Context, changeReq *models.PasswordChangeRequest) error
+	RequestReset(ctx context.Context, resetReq *models.PasswordResetRequest) error
+	ResetPassword(ctx context.Context, confirmation *models.PasswordResetConfirmation) error
+}
+
+// Notifier delivers password reset tokens to users. Package notify has
+// implementations for development.
+type Notifier interface {
+	NotifyPasswordReset(ctx context.Context, user models.User, token string, expiresAt time.Time) error
+}
+
+type SessionServiceInterface interface {
+	StartSession(ctx context.Context, user models.User) (*models.TokenPair, error)
+	Refresh(ctx context.Context, refreshToken string) (*models.TokenPair, error)
+	Logout(ctx context.Context, allSessions bool) error
+	RevokeUserSessions(ctx context.Context, username string) error
+}
+
+type APIKeyServiceInterface interface {
+	GetAPIKeys(ctx context.Context) ([]models.APIKey, error)
+	CreateAPIKey(ctx context.Context, keyReq *models.APIKeyRequest) (*models.CreatedAPIKey, error)
+	RevokeAPIKey(ctx context.Context, id string) (*models.APIKey, error)
+	AuthenticateAPIKey(ctx context.Context, key string) (*models.Principal, error)
+}
+
+type SSOServiceInterface interface {
+	Login(ctx context.Context, loginReq *models.OIDCLoginRequest) (*models.User, error)
+}
@@ -0,0 +1,173 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path"
+	"time"
+
+	"github.com/gloonch/CarZone/blob"
+	"github.com/gloonch/CarZone/models"
+	"github.com/gloonch/CarZone/service"
+	"github.com/gloonch/CarZone/store"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+)
+
+type MediaService struct {
+	store    store.MediaStoreInterface
+	carStore store.CarStoreInterface
+	blobs    blob.BlobStore
+	policy   service.CarPolicy
+}
+
+func NewMediaService(store store.MediaStoreInterface, carStore store.CarStoreInterface, blobs blob.BlobStore, policy service.CarPolicy) *MediaService {
+	return &MediaService{
+		store:    store,
+		carStore: carStore,
+		blobs:    blobs,
+		policy:   policy,
+	}
+}
+
+func (s *MediaService) GetMediaByCar(ctx context.Context, carID string) ([]models.CarMedia, error) {
+	tracer := otel.Tracer("media-service")
+	ctx, span := tracer.Start(ctx, "GetMediaByCar-Service")
+	defer span.End()
+
+	if _, err := s.carStore.GetCarByID(ctx, carID); err != nil {
+		return nil, err
+	}
+	return s.store.GetMediaByCar(ctx, carID)
+}
+
+// UploadMedia stores an attachment for a car the requesting user may modify.
+// The content type is sniffed from the bytes rather than trusted from the
+// client.
+func (s *MediaService) UploadMedia(ctx context.Context, carID string, fileName string, data []byte) (*models.CarMedia, error) {
+	tracer := otel.Tracer("media-service")
+	ctx, span := tracer.Start(ctx, "UploadMedia-Service")
+	defer span.End()
+
+	car, err := s.carStore.GetCarByID(ctx, carID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.policy.CanModify(ctx, car); err != nil {
+		return nil, err
+	}
+
+	contentType, _, err := mime.ParseMediaType(http.DetectContentType(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", models.ErrInvalidMedia, err)
+	}
+	fileName = path.Base(fileName)
+	if err := models.ValidateMediaUpload(fileName, contentType, int64(len(data))); err != nil {
+		return nil, fmt.Errorf("%w: %v", models.ErrInvalidMedia, err)
+	}
+	kind, _ := models.MediaKind(contentType)
+
+	media := models.CarMedia{
+		ID:          uuid.New(),
+		CarID:       car.ID,
+		Kind:        kind,
+		FileName:    fileName,
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		CreatedAt:   time.Now(),
+	}
+	media.BlobKey = path.Join("cars", car.ID.String(), media.ID.String())
+
+	var thumbnail []byte
+	if kind == models.MediaKindImage {
+		thumbnail, err = makeThumbnail(data)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", models.ErrInvalidMedia, err)
+		}
+		media.ThumbnailKey = media.BlobKey + "-thumbnail.jpg"
+	}
+
+	if err := s.blobs.Put(ctx, media.BlobKey, bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	if thumbnail != nil {
+		if err := s.blobs.Put(ctx, media.ThumbnailKey, bytes.NewReader(thumbnail)); err != nil {
+			s.removeBlobs(ctx, media)
+			return nil, err
+		}
+	}
+
+	createdMedia, err := s.store.CreateMedia(ctx, &media)
+	if err != nil {
+		s.removeBlobs(ctx, media)
+		return nil, err
+	}
+	return &createdMedia, nil
+}
+
+// OpenMedia returns the attachment's metadata with a reader for its bytes,
+// or for its thumbnail when thumbnail is set. The caller closes the reader.
+func (s *MediaService) OpenMedia(ctx context.Context, carID string, mediaID string, thumbnail bool) (*models.CarMedia, io.ReadCloser, error) {
+	tracer := otel.Tracer("media-service")
+	ctx, span := tracer.Start(ctx, "OpenMedia-Service")
+	defer span.End()
+
+	if _, err := s.carStore.GetCarByID(ctx, carID); err != nil {
+		return nil, nil, err
+	}
+	media, err := s.store.MediaByID(ctx, carID, mediaID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	key := media.BlobKey
+	if thumbnail {
+		if media.ThumbnailKey == "" {
+			return nil, nil, blob.ErrNotFound
+		}
+		key = media.ThumbnailKey
+		media.ContentType = "image/jpeg"
+	}
+
+	content, err := s.blobs.Get(ctx, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &media, content, nil
+}
+
+func (s *MediaService) DeleteMedia(ctx context.Context, carID string, mediaID string) (*models.CarMedia, error) {
+	tracer := otel.Tracer("media-service")
+	ctx, span := tracer.Start(ctx, "DeleteMedia-Service")
+	defer span.End()
+
+	car, err := s.carStore.GetCarByID(ctx, carID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.policy.CanModify(ctx, car); err != nil {
+		return nil, err
+	}
+	deletedMedia, err := s.store.DeleteMedia(ctx, carID, mediaID)
+	if err != nil {
+		return nil, err
+	}
+	s.removeBlobs(ctx, deletedMedia)
+	return &deletedMedia, nil
+}
+
+func (s *MediaService) removeBlobs(ctx context.Context, media models.CarMedia) {
+	for _, key := range []string{media.BlobKey, media.ThumbnailKey} {
+		if key == "" {
+			continue
+		}
+		if err := s.blobs.Delete(ctx, key); err != nil {
+			log.Printf("Error deleting blob %s: %v", key, err)
+		}
+	}
+}
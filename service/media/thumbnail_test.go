@@ -0,0 +1,48 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func encodePNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestMakeThumbnailFitsSquare(t *testing.T) {
+	thumbnail, err := makeThumbnail(encodePNG(t, 1024, 512))
+	if err != nil {
+		t.Fatalf("makeThumbnail: %v", err)
+	}
+	config, format, err := image.DecodeConfig(bytes.NewReader(thumbnail))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if format != "jpeg" || config.Width != thumbnailSize || config.Height != thumbnailSize/2 {
+		t.Fatalf("thumbnail is a %dx%d %s, want a %dx%d jpeg", config.Width, config.Height, format, thumbnailSize, thumbnailSize/2)
+	}
+}
+
+func TestMakeThumbnailRejectsHugeDimensions(t *testing.T) {
+	// A tiny PNG whose header claims 100000x100000 pixels.
+	data := encodePNG(t, 1, 1)
+	ihdr := data[8+8 : 8+8+13]
+	binary.BigEndian.PutUint32(ihdr[0:], 100000)
+	binary.BigEndian.PutUint32(ihdr[4:], 100000)
+	binary.BigEndian.PutUint32(data[8+8+13:], crc32.ChecksumIEEE(data[8+4:8+8+13]))
+
+	_, err := makeThumbnail(data)
+	if err == nil || !strings.Contains(err.Error(), "pixels") {
+		t.Fatalf("makeThumbnail of an image of 10 gigapixels: got %v, want the pixel limit", err)
+	}
+}
@@ -0,0 +1,117 @@
+package option
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gloonch/CarZone/models"
+	"github.com/gloonch/CarZone/service"
+	"github.com/gloonch/CarZone/store"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+)
+
+type OptionService struct {
+	store    store.OptionStoreInterface
+	carStore store.CarStoreInterface
+	rates    service.ExchangeRateServiceInterface
+	policy   service.CarPolicy
+}
+
+func NewOptionService(store store.OptionStoreInterface, carStore store.CarStoreInterface,
+	rates service.ExchangeRateServiceInterface, policy service.CarPolicy) *OptionService {
+	return &OptionService{
+		store:    store,
+		carStore: carStore,
+		rates:    rates,
+		policy:   policy,
+	}
+}
+
+// checkCar makes sure the requesting user may modify the car a package is
+// offered on. Packages of a whole trim belong to no single car and are left
+// to the editor role.
+func (s *OptionService) checkCar(ctx context.Context, carID *uuid.UUID) error {
+	if carID == nil {
+		return nil
+	}
+	car, err := s.carStore.GetCarByID(ctx, carID.String())
+	if err != nil {
+		return err
+	}
+	return s.policy.CanModify(ctx, car)
+}
+
+func (s *OptionService) GetPackagesForCar(ctx context.Context, carID string) ([]models.OptionPackage, error) {
+	tracer := otel.Tracer("option-service")
+	ctx, span := tracer.Start(ctx, "GetPackagesForCar-Service")
+	defer span.End()
+
+	return s.store.GetPackagesForCar(ctx, carID)
+}
+
+func (s *OptionService) CreatePackage(ctx context.Context, packageReq *models.OptionPackageRequest) (*models.OptionPackage, error) {
+	tracer := otel.Tracer("option-service")
+	ctx, span := tracer.Start(ctx, "CreatePackage-Service")
+	defer span.End()
+
+	packageReq.Normalize()
+	if err := models.ValidateOptionPackageRequest(*packageReq); err != nil {
+		return nil, err
+	}
+	if err := s.checkCar(ctx, packageReq.CarID); err != nil {
+		return nil, err
+	}
+
+	createdPackage, err := s.store.CreatePackage(ctx, packageReq)
+	if err != nil {
+		return nil, err
+	}
+	return &createdPackage, nil
+}
+
+func (s *OptionService) DeletePackage(ctx context.Context, id string) (*models.OptionPackage, error) {
+	tracer := otel.Tracer("option-service")
+	ctx, span := tracer.Start(ctx, "DeletePackage-Service")
+	defer span.End()
+
+	pkg, err := s.store.PackageByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkCar(ctx, pkg.CarID); err != nil {
+		return nil, err
+	}
+	deletedPackage, err := s.store.DeletePackage(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &deletedPackage, nil
+}
+
+// Configure checks a package selection against the rules of the packages
+// offered on the car and returns the resulting price.
+func (s *OptionService) Configure(ctx context.Context, carID string, configReq *models.ConfigurationRequest, currency string) (*models.Configuration, error) {
+	tracer := otel.Tracer("option-service")
+	ctx, span := tracer.Start(ctx, "Configure-Service")
+	defer span.End()
+
+	car, err := s.carStore.GetCarByID(ctx, carID)
+	if err != nil {
+		return nil, err
+	}
+	offered, err := s.store.GetPackagesForCar(ctx, carID)
+	if err != nil {
+		return nil, err
+	}
+
+	configuration, err := models.Configure(car, offered, configReq.Packages)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", models.ErrInvalidConfiguration, err)
+	}
+	configuration.ConvertedPrice, err = s.rates.ConvertPrice(ctx, configuration.TotalPrice, currency)
+	if err != nil {
+		return nil, err
+	}
+	return &configuration, nil
+}
@@ -0,0 +1,146 @@
+package password
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/gloonch/CarZone/middleware"
+	"github.com/gloonch/CarZone/models"
+	"github.com/gloonch/CarZone/service"
+	"github.com/gloonch/CarZone/store"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const resetTokenTTL = 30 * time.Minute
+
+type PasswordService struct {
+	users    store.UserStoreInterface
+	resets   store.PasswordResetStoreInterface
+	tokens   store.TokenStoreInterface
+	notifier service.Notifier
+}
+
+func NewPasswordService(users store.UserStoreInterface, resets store.PasswordResetStoreInterface,
+	tokens store.TokenStoreInterface, notifier service.Notifier) *PasswordService {
+	return &PasswordService{
+		users:    users,
+		resets:   resets,
+		tokens:   tokens,
+		notifier: notifier,
+	}
+}
+
+// ChangePassword sets a new password for the caller after checking the
+// current one, and signs the caller out everywhere. API keys cannot change
+// their owner's password.
+func (s *PasswordService) ChangePassword(ctx context.Context, changeReq *models.PasswordChangeRequest) error {
+	tracer := otel.Tracer("password-service")
+	ctx, span := tracer.Start(ctx, "ChangePassword-Service")
+	defer span.End()
+
+	username, err := middleware.RequireUsername(ctx)
+	if err != nil {
+		return err
+	}
+	if middleware.APIKeyFromContext(ctx) != "" {
+		return middleware.ErrForbidden
+	}
+	if err := models.ValidatePassword(changeReq.NewPassword); err != nil {
+		return err
+	}
+
+	user, err := s.users.GetUserByUsername(ctx, username)
+	if err != nil {
+		return err
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(changeReq.CurrentPassword)); err != nil {
+		return models.ErrInvalidCredentials
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(changeReq.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	if err := s.users.UpdatePassword(ctx, username, string(hash)); err != nil {
+		return err
+	}
+	return s.tokens.RevokeUserSessions(ctx, username, time.Now())
+}
+
+// RequestReset sends a single-use reset token to the user through the
+// notifier. Unknown users and SSO accounts, which have no password, are
+// silently ignored so the response does not reveal which accounts exist.
+func (s *PasswordService) RequestReset(ctx context.Context, resetReq *models.PasswordResetRequest) error {
+	tracer := otel.Tracer("password-service")
+	ctx, span := tracer.Start(ctx, "RequestReset-Service")
+	defer span.End()
+
+	userReq := models.UserRequest{Username: resetReq.Username}
+	userReq.Normalize()
+	user, err := s.users.GetUserByUsername(ctx, userReq.Username)
+	if errors.Is(err, models.ErrUserNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if user.PasswordHash == "" {
+		return nil
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return err
+	}
+	token := base64.RawURLEncoding.EncodeToString(secret)
+	now := time.Now()
+	record := models.PasswordResetToken{
+		ID:        uuid.New(),
+		Username:  user.Username,
+		TokenHash: hashToken(token),
+		ExpiresAt: now.Add(resetTokenTTL),
+		CreatedAt: now,
+	}
+	if err := s.resets.CreateResetToken(ctx, record); err != nil {
+		return err
+	}
+	return s.notifier.NotifyPasswordReset(ctx, user, token, record.ExpiresAt)
+}
+
+// ResetPassword sets a new password with a reset token and signs the user
+// out everywhere. Each token works once and only until it expires.
+func (s *PasswordService) ResetPassword(ctx context.Context, confirmation *models.PasswordResetConfirmation) error {
+	tracer := otel.Tracer("password-service")
+	ctx, span := tracer.Start(ctx, "ResetPassword-Service")
+	defer span.End()
+
+	if confirmation.Token == "" {
+		return models.ErrInvalidResetToken
+	}
+	if err := models.ValidatePassword(confirmation.NewPassword); err != nil {
+		return err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(confirmation.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	username, err := s.resets.ResetPassword(ctx, hashToken(confirmation.Token), string(hash), now)
+	if err != nil {
+		return err
+	}
+	return s.tokens.RevokeUserSessions(ctx, username, now)
+}
+
+// hashToken hashes a reset token for storage. The secret has 256 bits of
+// entropy, so a fast unsalted hash is enough.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,109 @@
+package quote
+
+import (
+	"context"
+	"time"
+
+	"github.com/gloonch/CarZone/middleware"
+	"github.com/gloonch/CarZone/models"
+	"github.com/gloonch/CarZone/store"
+	"go.opentelemetry.io/otel"
+)
+
+type QuoteService struct {
+	store       store.QuoteStoreInterface
+	carStore    store.CarStoreInterface
+	optionStore store.OptionStoreInterface
+}
+
+func NewQuoteService(store store.QuoteStoreInterface, carStore store.CarStoreInterface,
+	optionStore store.OptionStoreInterface) *QuoteService {
+	return &QuoteService{
+		store:       store,
+		carStore:    carStore,
+		optionStore: optionStore,
+	}
+}
+
+// CreateQuote prices a loan or lease for the car at its current price, plus
+// any selected option packages, and stores the quote under the requesting
+// user.
+func (s *QuoteService) CreateQuote(ctx context.Context, carID string, quoteReq *models.QuoteRequest) (*models.Quote, error) {
+	tracer := otel.Tracer("quote-service")
+	ctx, span := tracer.Start(ctx, "CreateQuote-Service")
+	defer span.End()
+
+	username, err := middleware.RequireUsername(ctx)
+	if err != nil {
+		return nil, err
+	}
+	car, err := s.carStore.GetCarByID(ctx, carID)
+	if err != nil {
+		return nil, err
+	}
+	packages := []string{}
+	if len(quoteReq.Packages) > 0 {
+		offered, err := s.optionStore.GetPackagesForCar(ctx, carID)
+		if err != nil {
+			return nil, err
+		}
+		configuration, err := models.Configure(car, offered, quoteReq.Packages)
+		if err != nil {
+			return nil, err
+		}
+		car.Price = configuration.TotalPrice
+		for _, pkg := range configuration.Packages {
+			packages = append(packages, pkg.Code)
+		}
+	}
+	if err := models.ValidateQuoteRequest(*quoteReq, car.Price); err != nil {
+		return nil, err
+	}
+
+	quote := models.BuildQuote(car, *quoteReq, time.Now())
+	quote.Username = username
+	quote.Packages = packages
+
+	createdQuote, err := s.store.CreateQuote(ctx, quote)
+	if err != nil {
+		return nil, err
+	}
+	return &createdQuote, nil
+}
+
+// GetQuote returns a quote to the user it was issued to and to admins of
+// the same tenant. Anyone else is told the quote does not exist.
+func (s *QuoteService) GetQuote(ctx context.Context, id string) (*models.Quote, error) {
+	tracer := otel.Tracer("quote-service")
+	ctx, span := tracer.Start(ctx, "GetQuote-Service")
+	defer span.End()
+
+	username, err := middleware.RequireUsername(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tenant, err := middleware.RequireTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+	quote, err := s.store.GetQuote(ctx, id, tenant)
+	if err != nil {
+		return nil, err
+	}
+	if quote.Username != username && !middleware.IsAdmin(ctx) {
+		return nil, models.ErrQuoteNotFound
+	}
+	return &quote, nil
+}
+
+func (s *QuoteService) GetMyQuotes(ctx context.Context) ([]models.Quote, error) {
+	tracer := otel.Tracer("quote-service")
+	ctx, span := tracer.Start(ctx, "GetMyQuotes-Service")
+	defer span.End()
+
+	username, err := middleware.RequireUsername(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.store.GetQuotesByUser(ctx, username)
+}
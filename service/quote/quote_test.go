@@ -0,0 +1,67 @@
+package quote
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gloonch/CarZone/models"
+	"github.com/gloonch/CarZone/store"
+	"github.com/google/uuid"
+)
+
+// tenantQuoteStore holds the quotes of a single tenant.
+type tenantQuoteStore struct {
+	store.QuoteStoreInterface
+	tenant string
+	quotes map[string]models.Quote
+}
+
+func (s tenantQuoteStore) GetQuote(ctx context.Context, id string, tenantID string) (models.Quote, error) {
+	quote, ok := s.quotes[id]
+	if !ok || tenantID != s.tenant {
+		return quote, models.ErrQuoteNotFound
+	}
+	return quote, nil
+}
+
+func requestContext(username, role, tenant string) context.Context {
+	ctx := context.WithValue(context.Background(), "username", username)
+	ctx = context.WithValue(ctx, "role", role)
+	return models.WithTenant(ctx, tenant)
+}
+
+func TestGetQuoteIsLimitedToItsUserAndTenantAdmins(t *testing.T) {
+	quote := models.Quote{ID: uuid.New(), Username: "alice"}
+	id := quote.ID.String()
+	service := NewQuoteService(tenantQuoteStore{
+		tenant: "tenant-a",
+		quotes: map[string]models.Quote{id: quote},
+	}, nil, nil)
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		allowed bool
+	}{
+		{"its user", requestContext("alice", models.RoleViewer, "tenant-a"), true},
+		{"an admin of the tenant", requestContext("root", models.RoleAdmin, "tenant-a"), true},
+		{"another user", requestContext("bob", models.RoleEditor, "tenant-a"), false},
+		{"an admin of another tenant", requestContext("root-b", models.RoleAdmin, "tenant-b"), false},
+		{"the same username in another tenant", requestContext("alice", models.RoleViewer, "tenant-b"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := service.GetQuote(tt.ctx, id)
+			if tt.allowed {
+				if err != nil || got.ID != quote.ID {
+					t.Fatalf("GetQuote: got %v, %v", got, err)
+				}
+				return
+			}
+			if !errors.Is(err, models.ErrQuoteNotFound) {
+				t.Fatalf("GetQuote: got %v, want %v", err, models.ErrQuoteNotFound)
+			}
+		})
+	}
+}
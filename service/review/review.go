@@ -0,0 +1,100 @@
+package review
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gloonch/CarZone/middleware"
+	"github.com/gloonch/CarZone/models"
+	"github.com/gloonch/CarZone/store"
+	"go.opentelemetry.io/otel"
+)
+
+type ReviewService struct {
+	store store.ReviewStoreInterface
+}
+
+func NewReviewService(store store.ReviewStoreInterface) *ReviewService {
+	return &ReviewService{
+		store: store,
+	}
+}
+
+func (s *ReviewService) GetCarReviews(ctx context.Context, carID string) ([]models.Review, error) {
+	tracer := otel.Tracer("review-service")
+	ctx, span := tracer.Start(ctx, "GetCarReviews-Service")
+	defer span.End()
+
+	return s.store.GetReviewsForCar(ctx, carID)
+}
+
+// GetReviews lists reviews in a moderation state, pending ones by default.
+func (s *ReviewService) GetReviews(ctx context.Context, status string) ([]models.Review, error) {
+	tracer := otel.Tracer("review-service")
+	ctx, span := tracer.Start(ctx, "GetReviews-Service")
+	defer span.End()
+
+	if status == "" {
+		status = models.ReviewStatusPending
+	}
+	if err := models.ValidateReviewStatus(status); err != nil {
+		return nil, err
+	}
+	return s.store.GetReviewsByStatus(ctx, status)
+}
+
+// CreateReview records a review as pending; it only counts towards ratings
+// once a moderator approves it.
+func (s *ReviewService) CreateReview(ctx context.Context, reviewReq *models.ReviewRequest) (*models.Review, error) {
+	tracer := otel.Tracer("review-service")
+	ctx, span := tracer.Start(ctx, "CreateReview-Service")
+	defer span.End()
+
+	username, err := middleware.RequireUsername(ctx)
+	if err != nil {
+		return nil, err
+	}
+	reviewReq.Brand = strings.TrimSpace(reviewReq.Brand)
+	reviewReq.Model = strings.TrimSpace(reviewReq.Model)
+	if err := models.ValidateReviewRequest(*reviewReq); err != nil {
+		return nil, err
+	}
+
+	createdReview, err := s.store.CreateReview(ctx, username, reviewReq)
+	if err != nil {
+		return nil, err
+	}
+	return &createdReview, nil
+}
+
+func (s *ReviewService) ModerateReview(ctx context.Context, id string, moderationReq *models.ModerationRequest) (*models.Review, error) {
+	tracer := otel.Tracer("review-service")
+	ctx, span := tracer.Start(ctx, "ModerateReview-Service")
+	defer span.End()
+
+	if err := models.ValidateReviewStatus(moderationReq.Status); err != nil {
+		return nil, err
+	}
+	updatedReview, err := s.store.UpdateReviewStatus(ctx, id, moderationReq.Status)
+	if err != nil {
+		return nil, err
+	}
+	return &updatedReview, nil
+}
+
+// DeleteReview removes one of the current user's own reviews.
+func (s *ReviewService) DeleteReview(ctx context.Context, id string) (*models.Review, error) {
+	tracer := otel.Tracer("review-service")
+	ctx, span := tracer.Start(ctx, "DeleteReview-Service")
+	defer span.End()
+
+	username, err := middleware.RequireUsername(ctx)
+	if err != nil {
+		return nil, err
+	}
+	deletedReview, err := s.store.DeleteReview(ctx, username, id)
+	if err != nil {
+		return nil, err
+	}
+	return &deletedReview, nil
+}
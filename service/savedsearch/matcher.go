@@ -0,0 +1,95 @@
+package savedsearch
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/gloonch/CarZone/models"
+	"github.com/gloonch/CarZone/store"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+)
+
+type carChange struct {
+	car    models.Car
+	event  string
+	tenant string
+}
+
+// Matcher checks changed cars against the saved searches of the car's tenant
+// in the background and records a notification for each match. CarService hands it changes
+// through NotifyCarChanged so requests never wait on matching.
+type Matcher struct {
+	store   store.SavedSearchStoreInterface
+	changes chan carChange
+}
+
+func NewMatcher(store store.SavedSearchStoreInterface, queueSize int) *Matcher {
+	return &Matcher{
+		store:   store,
+		changes: make(chan carChange, queueSize),
+	}
+}
+
+// NotifyCarChanged queues a change for matching against the searches of the
+// tenant in ctx. It never blocks; when the queue is full the change is
+// dropped and logged.
+func (m *Matcher) NotifyCarChanged(ctx context.Context, car models.Car, event string) {
+	tenant := models.TenantFromContext(ctx)
+	if tenant == "" {
+		log.Printf("No tenant for %s event of car %s, not matching saved searches", event, car.ID)
+		return
+	}
+
+	select {
+	case m.changes <- carChange{car: car, event: event, tenant: tenant}:
+	default:
+		log.Printf("Saved search matcher queue full, dropping %s event for car %s", event, car.ID)
+	}
+}
+
+// Run processes queued changes until ctx is cancelled.
+func (m *Matcher) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case change := <-m.changes:
+			if err := m.match(ctx, change); err != nil {
+				log.Printf("Error matching saved searches for car %s: %v", change.car.ID, err)
+			}
+		}
+	}
+}
+
+func (m *Matcher) match(ctx context.Context, change carChange) error {
+	tracer := otel.Tracer("saved-search-matcher")
+	ctx, span := tracer.Start(ctx, "MatchSavedSearches")
+	defer span.End()
+
+	searches, err := m.store.GetTenantSavedSearches(ctx, change.tenant)
+	if err != nil {
+		return err
+	}
+
+	var notifications []models.Notification
+	for _, search := range searches {
+		if !search.Filter.Matches(change.car) {
+			continue
+		}
+		notifications = append(notifications, models.Notification{
+			ID:            uuid.New(),
+			Username:      search.Username,
+			SavedSearchID: search.ID,
+			SearchName:    search.Name,
+			CarID:         change.car.ID,
+			Event:         change.event,
+			CreatedAt:     time.Now(),
+		})
+	}
+	if len(notifications) == 0 {
+		return nil
+	}
+	return m.store.CreateNotifications(ctx, notifications)
+}
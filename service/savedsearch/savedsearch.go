@@ -0,0 +1,94 @@
+package savedsearch
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gloonch/CarZone/middleware"
+	"github.com/gloonch/CarZone/models"
+	"github.com/gloonch/CarZone/store"
+	"go.opentelemetry.io/otel"
+)
+
+type SavedSearchService struct {
+	store store.SavedSearchStoreInterface
+}
+
+func NewSavedSearchService(store store.SavedSearchStoreInterface) *SavedSearchService {
+	return &SavedSearchService{
+		store: store,
+	}
+}
+
+func (s *SavedSearchService) GetSavedSearches(ctx context.Context) ([]models.SavedSearch, error) {
+	tracer := otel.Tracer("saved-search-service")
+	ctx, span := tracer.Start(ctx, "GetSavedSearches-Service")
+	defer span.End()
+
+	username, err := middleware.RequireUsername(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.store.GetSavedSearches(ctx, username)
+}
+
+func (s *SavedSearchService) CreateSavedSearch(ctx context.Context, searchReq *models.SavedSearchRequest) (*models.SavedSearch, error) {
+	tracer := otel.Tracer("saved-search-service")
+	ctx, span := tracer.Start(ctx, "CreateSavedSearch-Service")
+	defer span.End()
+
+	username, err := middleware.RequireUsername(ctx)
+	if err != nil {
+		return nil, err
+	}
+	searchReq.Filter.Currency = strings.ToUpper(searchReq.Filter.Currency)
+	if err := models.ValidateSavedSearchRequest(*searchReq); err != nil {
+		return nil, err
+	}
+
+	createdSearch, err := s.store.CreateSavedSearch(ctx, username, searchReq)
+	if err != nil {
+		return nil, err
+	}
+	return &createdSearch, nil
+}
+
+func (s *SavedSearchService) DeleteSavedSearch(ctx context.Context, id string) (*models.SavedSearch, error) {
+	tracer := otel.Tracer("saved-search-service")
+	ctx, span := tracer.Start(ctx, "DeleteSavedSearch-Service")
+	defer span.End()
+
+	username, err := middleware.RequireUsername(ctx)
+	if err != nil {
+		return nil, err
+	}
+	deletedSearch, err := s.store.DeleteSavedSearch(ctx, username, id)
+	if err != nil {
+		return nil, err
+	}
+	return &deletedSearch, nil
+}
+
+func (s *SavedSearchService) GetNotifications(ctx context.Context, unreadOnly bool) ([]models.Notification, error) {
+	tracer := otel.Tracer("saved-search-service")
+	ctx, span := tracer.Start(ctx, "GetNotifications-Service")
+	defer span.End()
+
+	username, err := middleware.RequireUsername(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.store.GetNotifications(ctx, username, unreadOnly)
+}
+
+func (s *SavedSearchService) MarkNotificationRead(ctx context.Context, id string) error {
+	tracer := otel.Tracer("saved-search-service")
+	ctx, span := tracer.Start(ctx, "MarkNotificationRead-Service")
+	defer span.End()
+
+	username, err := middleware.RequireUsername(ctx)
+	if err != nil {
+		return err
+	}
+	return s.store.MarkNotificationRead(ctx, username, id)
+}
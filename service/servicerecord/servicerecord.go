@@ -0,0 +1,122 @@
+package servicerecord
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/gloonch/CarZone/models"
+	"github.com/gloonch/CarZone/service"
+	"github.com/gloonch/CarZone/store"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+)
+
+type ServiceRecordService struct {
+	store    store.ServiceRecordStoreInterface
+	carStore store.CarStoreInterface
+	policy   service.CarPolicy
+}
+
+func NewServiceRecordService(store store.ServiceRecordStoreInterface, carStore store.CarStoreInterface, policy service.CarPolicy) *ServiceRecordService {
+	return &ServiceRecordService{
+		store:    store,
+		carStore: carStore,
+		policy:   policy,
+	}
+}
+
+// modifiableCar returns the car if the requesting user may modify it.
+func (s *ServiceRecordService) modifiableCar(ctx context.Context, carID string) (models.Car, error) {
+	car, err := s.carStore.GetCarByID(ctx, carID)
+	if err != nil {
+		return car, err
+	}
+	return car, s.policy.CanModify(ctx, car)
+}
+
+func (s *ServiceRecordService) GetServiceRecords(ctx context.Context, carID string) ([]models.ServiceRecord, error) {
+	tracer := otel.Tracer("service-record-service")
+	ctx, span := tracer.Start(ctx, "GetServiceRecords-Service")
+	defer span.End()
+
+	if _, err := s.carStore.GetCarByID(ctx, carID); err != nil {
+		return nil, err
+	}
+	return s.store.GetServiceRecords(ctx, carID)
+}
+
+func (s *ServiceRecordService) CreateServiceRecord(ctx context.Context, carID string, recordReq *models.ServiceRecordRequest) (*models.ServiceRecord, error) {
+	tracer := otel.Tracer("service-record-service")
+	ctx, span := tracer.Start(ctx, "CreateServiceRecord-Service")
+	defer span.End()
+
+	recordReq.Cost.Currency = strings.ToUpper(recordReq.Cost.Currency)
+	if err := models.ValidateServiceRecordRequest(*recordReq); err != nil {
+		return nil, err
+	}
+	if _, err := s.modifiableCar(ctx, carID); err != nil {
+		return nil, err
+	}
+
+	createdRecord, err := s.store.CreateServiceRecord(ctx, carID, recordReq)
+	if err != nil {
+		return nil, err
+	}
+	return &createdRecord, nil
+}
+
+func (s *ServiceRecordService) DeleteServiceRecord(ctx context.Context, carID string, id string) (*models.ServiceRecord, error) {
+	tracer := otel.Tracer("service-record-service")
+	ctx, span := tracer.Start(ctx, "DeleteServiceRecord-Service")
+	defer span.End()
+
+	if _, err := s.modifiableCar(ctx, carID); err != nil {
+		return nil, err
+	}
+	deletedRecord, err := s.store.DeleteServiceRecord(ctx, carID, id)
+	if err != nil {
+		return nil, err
+	}
+	return &deletedRecord, nil
+}
+
+// GetServiceDue lists the cars whose last service is at least maxDays old
+// or maxDistance km ago, plus cars with no service history.
+func (s *ServiceRecordService) GetServiceDue(ctx context.Context, maxDays int, maxDistance int64) ([]models.ServiceDue, error) {
+	tracer := otel.Tracer("service-record-service")
+	ctx, span := tracer.Start(ctx, "GetServiceDue-Service")
+	defer span.End()
+
+	if err := models.ValidateServiceInterval(maxDays, maxDistance); err != nil {
+		return nil, err
+	}
+
+	cars, err := s.carStore.GetAllCars(ctx)
+	if err != nil {
+		return nil, err
+	}
+	latest, err := s.store.LatestServiceRecords(ctx)
+	if err != nil {
+		return nil, err
+	}
+	lastByCar := make(map[uuid.UUID]*models.ServiceRecord, len(latest))
+	for i := range latest {
+		lastByCar[latest[i].CarID] = &latest[i]
+	}
+
+	now := time.Now()
+	dueCars := []models.ServiceDue{}
+	for _, car := range cars {
+		mileage := car.Mileage
+		due, isDue := models.CheckServiceDue(lastByCar[car.ID], &mileage, now, maxDays, maxDistance)
+		if !isDue {
+			continue
+		}
+		due.CarID = car.ID
+		due.Name = car.Name
+		due.Brand = car.Brand
+		dueCars = append(dueCars, due)
+	}
+	return dueCars, nil
+}
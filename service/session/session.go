@@ -0,0 +1,183 @@
+package session
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/gloonch/CarZone/middleware"
+	"github.com/gloonch/CarZone/models"
+	"github.com/gloonch/CarZone/store"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+)
+
+const (
+	accessTokenTTL  = 15 * time.Minute
+	refreshTokenTTL = 30 * 24 * time.Hour
+)
+
+// TokenSigner signs access tokens; *middleware.KeySet implements it.
+type TokenSigner interface {
+	Sign(claims jwt.Claims) (string, error)
+}
+
+type SessionService struct {
+	tokens store.TokenStoreInterface
+	users  store.UserStoreInterface
+	signer TokenSigner
+}
+
+func NewSessionService(tokens store.TokenStoreInterface, users store.UserStoreInterface, signer TokenSigner) *SessionService {
+	return &SessionService{
+		tokens: tokens,
+		users:  users,
+		signer: signer,
+	}
+}
+
+// StartSession issues the first token pair of a new login session.
+func (s *SessionService) StartSession(ctx context.Context, user models.User) (*models.TokenPair, error) {
+	tracer := otel.Tracer("session-service")
+	ctx, span := tracer.Start(ctx, "StartSession-Service")
+	defer span.End()
+
+	return s.issue(ctx, user, uuid.New())
+}
+
+// Refresh exchanges a refresh token for a new pair in the same session. Each
+// refresh token works once; presenting a used one again revokes the whole
+// session, since either the client or an attacker holds a stolen copy.
+func (s *SessionService) Refresh(ctx context.Context, refreshToken string) (*models.TokenPair, error) {
+	tracer := otel.Tracer("session-service")
+	ctx, span := tracer.Start(ctx, "Refresh-Service")
+	defer span.End()
+
+	now := time.Now()
+	token, err := s.tokens.UseRefreshToken(ctx, hashToken(refreshToken), now)
+	if errors.Is(err, models.ErrRefreshTokenReused) {
+		log.Printf("Refresh token reused in session %s of %s; revoking the session", token.SessionID, token.Username)
+		if err := s.tokens.RevokeSession(ctx, token.SessionID.String(), now); err != nil {
+			return nil, err
+		}
+		return nil, models.ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Reload the user so role changes apply and removed users are locked out.
+	user, err := s.users.GetUserByUsername(ctx, token.Username)
+	if err != nil {
+		if errors.Is(err, models.ErrUserNotFound) {
+			return nil, models.ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+	return s.issue(ctx, user, token.SessionID)
+}
+
+// Logout revokes the caller's session, or all of the caller's sessions.
+func (s *SessionService) Logout(ctx context.Context, allSessions bool) error {
+	tracer := otel.Tracer("session-service")
+	ctx, span := tracer.Start(ctx, "Logout-Service")
+	defer span.End()
+
+	username, err := middleware.RequireUsername(ctx)
+	if err != nil {
+		return err
+	}
+	if allSessions {
+		return s.tokens.RevokeUserSessions(ctx, username, time.Now())
+	}
+	sessionID := middleware.SessionFromContext(ctx)
+	if sessionID == "" {
+		return models.ErrNoSession
+	}
+	return s.tokens.RevokeSession(ctx, sessionID, time.Now())
+}
+
+// RevokeUserSessions signs a user out everywhere, e.g. after a device was
+// lost. Only admins may revoke other users' sessions.
+func (s *SessionService) RevokeUserSessions(ctx context.Context, username string) error {
+	tracer := otel.Tracer("session-service")
+	ctx, span := tracer.Start(ctx, "RevokeUserSessions-Service")
+	defer span.End()
+
+	if !middleware.IsAdmin(ctx) {
+		return middleware.ErrForbidden
+	}
+	userReq := models.UserRequest{Username: username}
+	userReq.Normalize()
+	user, err := s.users.GetUserByUsername(ctx, userReq.Username)
+	if err != nil {
+		return err
+	}
+	if user.TenantID != middleware.TenantFromContext(ctx) {
+		return models.ErrUserNotFound
+	}
+	return s.tokens.RevokeUserSessions(ctx, userReq.Username, time.Now())
+}
+
+func (s *SessionService) issue(ctx context.Context, user models.User, sessionID uuid.UUID) (*models.TokenPair, error) {
+	now := time.Now()
+	jti := uuid.New().String()
+	accessExpiresAt := now.Add(accessTokenTTL)
+
+	accessToken, err := s.signer.Sign(&middleware.Claims{
+		Username:  user.Username,
+		Role:      user.Role,
+		TenantID:  user.TenantID,
+		SessionID: sessionID.String(),
+		StandardClaims: jwt.StandardClaims{
+			Id:        jti,
+			ExpiresAt: accessExpiresAt.Unix(),
+			IssuedAt:  now.Unix(),
+			Subject:   user.Username,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	refreshToken := base64.RawURLEncoding.EncodeToString(secret)
+
+	record := models.RefreshToken{
+		ID:              uuid.New(),
+		SessionID:       sessionID,
+		Username:        user.Username,
+		TokenHash:       hashToken(refreshToken),
+		AccessJTI:       jti,
+		AccessExpiresAt: accessExpiresAt,
+		ExpiresAt:       now.Add(refreshTokenTTL),
+		CreatedAt:       now,
+	}
+	if err := s.tokens.CreateRefreshToken(ctx, record); err != nil {
+		return nil, err
+	}
+
+	return &models.TokenPair{
+		AccessToken:      accessToken,
+		TokenType:        "Bearer",
+		ExpiresIn:        int64(accessTokenTTL.Seconds()),
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: record.ExpiresAt,
+	}, nil
+}
+
+// hashToken hashes a refresh token for storage. The secret has 256 bits of
+// entropy, so a fast unsalted hash is enough.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
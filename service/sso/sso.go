@@ -0,0 +1,111 @@
+package sso
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gloonch/CarZone/models"
+	"github.com/gloonch/CarZone/store"
+	"go.opentelemetry.io/otel"
+)
+
+// NoRole as the default role refuses users whose groups map to no role.
+const NoRole = "none"
+
+// IDTokenVerifier checks an ID token; *oidc.Provider implements it.
+type IDTokenVerifier interface {
+	Verify(ctx context.Context, rawIDToken string, nonce string) (*models.OIDCIdentity, error)
+}
+
+type SSOService struct {
+	verifier    IDTokenVerifier
+	users       store.UserStoreInterface
+	groupRoles  map[string]string
+	defaultRole string
+	// tenantID is the tenant new SSO accounts are created in.
+	tenantID string
+}
+
+func NewSSOService(verifier IDTokenVerifier, users store.UserStoreInterface, groupRoles map[string]string, defaultRole string, tenantID string) *SSOService {
+	return &SSOService{
+		verifier:    verifier,
+		users:       users,
+		groupRoles:  groupRoles,
+		defaultRole: defaultRole,
+		tenantID:    tenantID,
+	}
+}
+
+// ParseGroupRoles parses a mapping such as
+// "carzone-admins=admin,carzone-editors=editor".
+func ParseGroupRoles(mapping string) (map[string]string, error) {
+	groupRoles := map[string]string{}
+	for _, pair := range strings.Split(mapping, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		group, role, ok := strings.Cut(pair, "=")
+		group, role = strings.TrimSpace(group), strings.TrimSpace(role)
+		if !ok || group == "" {
+			return nil, fmt.Errorf("invalid group mapping %q; use group=role", pair)
+		}
+		if err := models.ValidateRole(role); err != nil {
+			return nil, fmt.Errorf("group %s: %w", group, err)
+		}
+		groupRoles[group] = role
+	}
+	return groupRoles, nil
+}
+
+// Login verifies an ID token and returns the linked CarZone account, which
+// is created on first login. The role is the highest one mapped from the
+// user's groups, or the default role.
+func (s *SSOService) Login(ctx context.Context, loginReq *models.OIDCLoginRequest) (*models.User, error) {
+	tracer := otel.Tracer("sso-service")
+	ctx, span := tracer.Start(ctx, "Login-Service")
+	defer span.End()
+
+	identity, err := s.verifier.Verify(ctx, loginReq.IDToken, loginReq.Nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	role := ""
+	for _, group := range identity.Groups {
+		if groupRole, ok := s.groupRoles[group]; ok && (role == "" || models.RoleAtLeast(groupRole, role)) {
+			role = groupRole
+		}
+	}
+	if role == "" {
+		role = s.defaultRole
+	}
+	if role == NoRole {
+		return nil, models.ErrNoRole
+	}
+
+	username, err := usernameFor(*identity)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.users.UpsertOIDCUser(ctx, identity.Subject, username, role, s.tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// usernameFor derives a CarZone username from the preferred username or
+// e-mail address, e.g. jane.doe@corp.example becomes jane.doe.corp.example.
+func usernameFor(identity models.OIDCIdentity) (string, error) {
+	name := identity.PreferredUsername
+	if name == "" {
+		name = identity.Email
+	}
+	userReq := models.UserRequest{Username: strings.ReplaceAll(name, "@", ".")}
+	userReq.Normalize()
+	if err := models.ValidateUsername(userReq.Username); err != nil {
+		return "", fmt.Errorf("the identity provider's username %q cannot be used: %w", name, err)
+	}
+	return userReq.Username, nil
+}
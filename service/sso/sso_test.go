@@ -0,0 +1,141 @@
+package sso
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gloonch/CarZone/models"
+	"github.com/gloonch/CarZone/oidc"
+	"github.com/gloonch/CarZone/oidc/oidctest"
+	"github.com/gloonch/CarZone/store"
+)
+
+// oidcUserStore records the accounts UpsertOIDCUser is asked for.
+type oidcUserStore struct {
+	store.UserStoreInterface
+	upserted []models.User
+}
+
+func (s *oidcUserStore) UpsertOIDCUser(ctx context.Context, subject string, username string, role string, tenantID string) (models.User, error) {
+	user := models.User{Username: username, Role: role, TenantID: tenantID}
+	s.upserted = append(s.upserted, user)
+	return user, nil
+}
+
+func newTestSSO(t *testing.T, defaultRole string) (*SSOService, *oidctest.Issuer, *oidcUserStore) {
+	t.Helper()
+
+	issuer, err := oidctest.NewIssuer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(issuer.Close)
+
+	provider, err := oidc.NewProvider(oidc.Config{IssuerURL: issuer.URL(), ClientID: "carzone"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	groupRoles, err := ParseGroupRoles("carzone-admins=admin, carzone-editors=editor, carzone-staff=viewer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	users := &oidcUserStore{}
+	return NewSSOService(provider, users, groupRoles, defaultRole, "dealer-1"), issuer, users
+}
+
+func TestLoginMapsGroupsToRoles(t *testing.T) {
+	tests := []struct {
+		name        string
+		groups      []string
+		defaultRole string
+		wantRole    string
+	}{
+		{"highest mapped role wins", []string{"carzone-staff", "carzone-admins", "carzone-editors"}, models.RoleViewer, models.RoleAdmin},
+		{"unmapped groups are ignored", []string{"payroll", "carzone-editors"}, models.RoleViewer, models.RoleEditor},
+		{"no mapped group gets the default role", []string{"payroll"}, models.RoleViewer, models.RoleViewer},
+		{"a mapped group overrides a none default", []string{"carzone-staff"}, NoRole, models.RoleViewer},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, issuer, users := newTestSSO(t, tt.defaultRole)
+			token, err := issuer.IDToken("subject-1", "carzone", map[string]interface{}{
+				"preferred_username": "jane",
+				"groups":             tt.groups,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			user, err := service.Login(context.Background(), &models.OIDCLoginRequest{IDToken: token})
+			if err != nil {
+				t.Fatalf("Login: %v", err)
+			}
+			if user.Role != tt.wantRole || user.Username != "jane" || user.TenantID != "dealer-1" {
+				t.Fatalf("Login: got %+v, want jane as %s in dealer-1", user, tt.wantRole)
+			}
+			if len(users.upserted) != 1 {
+				t.Fatalf("UpsertOIDCUser was called %d times", len(users.upserted))
+			}
+		})
+	}
+}
+
+func TestLoginRefusesUsersWithoutRoleWhenDefaultIsNone(t *testing.T) {
+	service, issuer, users := newTestSSO(t, NoRole)
+	token, err := issuer.IDToken("subject-1", "carzone", map[string]interface{}{
+		"preferred_username": "jane",
+		"groups":             []string{"payroll"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := service.Login(context.Background(), &models.OIDCLoginRequest{IDToken: token}); !errors.Is(err, models.ErrNoRole) {
+		t.Fatalf("Login: got %v, want %v", err, models.ErrNoRole)
+	}
+	if len(users.upserted) != 0 {
+		t.Fatal("an account was created for a user without a role")
+	}
+}
+
+func TestLoginRejectsInvalidTokens(t *testing.T) {
+	service, issuer, users := newTestSSO(t, models.RoleViewer)
+	wrongAudience, err := issuer.IDToken("subject-1", "another-client", map[string]interface{}{"preferred_username": "jane"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonce, err := issuer.IDToken("subject-1", "carzone", map[string]interface{}{"preferred_username": "jane", "nonce": "n-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, loginReq := range []models.OIDCLoginRequest{
+		{IDToken: wrongAudience},
+		{IDToken: nonce, Nonce: "n-2"},
+		{IDToken: "not-a-token"},
+	} {
+		if _, err := service.Login(context.Background(), &loginReq); !errors.Is(err, models.ErrInvalidIDToken) {
+			t.Errorf("Login: got %v, want %v", err, models.ErrInvalidIDToken)
+		}
+	}
+	if len(users.upserted) != 0 {
+		t.Fatal("an account was created from an invalid token")
+	}
+}
+
+func TestLoginDerivesUsernameFromEmail(t *testing.T) {
+	service, issuer, _ := newTestSSO(t, models.RoleViewer)
+	token, err := issuer.IDToken("subject-1", "carzone", map[string]interface{}{"email": "Jane.Doe@corp.example"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := service.Login(context.Background(), &models.OIDCLoginRequest{IDToken: token})
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	if user.Username != "jane.doe.corp.example" {
+		t.Fatalf("username: got %q, want %q", user.Username, "jane.doe.corp.example")
+	}
+}
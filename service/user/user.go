@@ -0,0 +1,123 @@
+package user
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gloonch/CarZone/middleware"
+	"github.com/gloonch/CarZone/models"
+	"github.com/gloonch/CarZone/store"
+	"go.opentelemetry.io/otel"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// dummyHash is compared against when a username is unknown so that failed
+// logins take the same time whether or not the account exists.
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("carzone-dummy-password"), bcrypt.DefaultCost)
+
+type UserService struct {
+	store store.UserStoreInterface
+}
+
+func NewUserService(store store.UserStoreInterface) *UserService {
+	return &UserService{
+		store: store,
+	}
+}
+
+// Authenticate returns the user whose password matches, or
+// models.ErrInvalidCredentials.
+func (s *UserService) Authenticate(ctx context.Context, credentials models.Credentials) (*models.User, error) {
+	tracer := otel.Tracer("user-service")
+	ctx, span := tracer.Start(ctx, "Authenticate-Service")
+	defer span.End()
+
+	userReq := models.UserRequest{Username: credentials.UserName}
+	userReq.Normalize()
+
+	user, err := s.store.GetUserByUsername(ctx, userReq.Username)
+	if err != nil {
+		if errors.Is(err, models.ErrUserNotFound) {
+			_ = bcrypt.CompareHashAndPassword(dummyHash, []byte(credentials.Password))
+			return nil, models.ErrInvalidCredentials
+		}
+		return nil, err
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(credentials.Password)); err != nil {
+		return nil, models.ErrInvalidCredentials
+	}
+	return &user, nil
+}
+
+func (s *UserService) GetUsers(ctx context.Context) ([]models.User, error) {
+	tracer := otel.Tracer("user-service")
+	ctx, span := tracer.Start(ctx, "GetUsers-Service")
+	defer span.End()
+
+	if !middleware.IsAdmin(ctx) {
+		return nil, middleware.ErrForbidden
+	}
+	tenant, err := middleware.RequireTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.store.GetUsers(ctx, tenant)
+}
+
+// CreateUser registers an account in the admin's own tenant. Only admins of
+// the default tenant may register users in other tenants.
+func (s *UserService) CreateUser(ctx context.Context, userReq *models.UserRequest) (*models.User, error) {
+	tracer := otel.Tracer("user-service")
+	ctx, span := tracer.Start(ctx, "CreateUser-Service")
+	defer span.End()
+
+	if !middleware.IsAdmin(ctx) {
+		return nil, middleware.ErrForbidden
+	}
+	tenant, err := middleware.RequireTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if userReq.TenantID == "" {
+		userReq.TenantID = tenant
+	}
+	if userReq.TenantID != tenant && tenant != models.DefaultTenant {
+		return nil, middleware.ErrForbidden
+	}
+	return s.createUser(ctx, userReq)
+}
+
+func (s *UserService) createUser(ctx context.Context, userReq *models.UserRequest) (*models.User, error) {
+	userReq.Normalize()
+	if err := models.ValidateUserRequest(*userReq); err != nil {
+		return nil, err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(userReq.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+	createdUser, err := s.store.CreateUser(ctx, userReq.Username, string(hash), userReq.Role, userReq.TenantID, userReq.Location)
+	if err != nil {
+		return nil, err
+	}
+	return &createdUser, nil
+}
+
+// EnsureAdmin creates the first admin account when no users exist yet, so a
+// fresh deployment can be logged into. The admin belongs to the default
+// tenant. It does nothing once any user exists.
+func (s *UserService) EnsureAdmin(ctx context.Context, username string, password string) error {
+	count, err := s.store.CountUsers(ctx)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	if password == "" {
+		return errors.New("no users exist; set ADMIN_PASSWORD to create the first admin")
+	}
+	_, err = s.createUser(ctx, &models.UserRequest{Username: username, Password: password, Role: models.RoleAdmin, TenantID: models.DefaultTenant})
+	return err
+}
@@ -0,0 +1,101 @@
+package valuation
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gloonch/CarZone/middleware"
+	"github.com/gloonch/CarZone/models"
+	"github.com/gloonch/CarZone/service"
+	"github.com/gloonch/CarZone/store"
+	"go.opentelemetry.io/otel"
+)
+
+type ValuationService struct {
+	store    store.DepreciationStoreInterface
+	carStore store.CarStoreInterface
+	rates    service.ExchangeRateServiceInterface
+}
+
+func NewValuationService(store store.DepreciationStoreInterface, carStore store.CarStoreInterface,
+	rates service.ExchangeRateServiceInterface) *ValuationService {
+	return &ValuationService{
+		store:    store,
+		carStore: carStore,
+		rates:    rates,
+	}
+}
+
+// ValuateCar estimates the current value of a car using the depreciation
+// curve of its brand.
+func (s *ValuationService) ValuateCar(ctx context.Context, carID string, currency string) (*models.Valuation, error) {
+	tracer := otel.Tracer("valuation-service")
+	ctx, span := tracer.Start(ctx, "ValuateCar-Service")
+	defer span.End()
+
+	car, err := s.carStore.GetCarByID(ctx, carID)
+	if err != nil {
+		return nil, err
+	}
+	curve, err := s.store.CurveForBrand(ctx, car.Brand)
+	if err != nil {
+		return nil, err
+	}
+
+	valuation, err := models.Valuate(car, curve, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	valuation.ConvertedValue, err = s.rates.ConvertPrice(ctx, valuation.EstimatedValue, currency)
+	if err != nil {
+		return nil, err
+	}
+	return &valuation, nil
+}
+
+func (s *ValuationService) GetCurves(ctx context.Context) ([]models.DepreciationCurve, error) {
+	tracer := otel.Tracer("valuation-service")
+	ctx, span := tracer.Start(ctx, "GetCurves-Service")
+	defer span.End()
+
+	return s.store.GetCurves(ctx)
+}
+
+func (s *ValuationService) SaveCurve(ctx context.Context, brand string, curveReq *models.DepreciationCurveRequest) (*models.DepreciationCurve, error) {
+	tracer := otel.Tracer("valuation-service")
+	ctx, span := tracer.Start(ctx, "SaveCurve-Service")
+	defer span.End()
+
+	if !middleware.IsAdmin(ctx) {
+		return nil, middleware.ErrForbidden
+	}
+	if err := models.ValidateDepreciationCurveRequest(*curveReq); err != nil {
+		return nil, err
+	}
+
+	savedCurve, err := s.store.SaveCurve(ctx, brand, curveReq)
+	if err != nil {
+		return nil, err
+	}
+	return &savedCurve, nil
+}
+
+func (s *ValuationService) DeleteCurve(ctx context.Context, brand string) (*models.DepreciationCurve, error) {
+	tracer := otel.Tracer("valuation-service")
+	ctx, span := tracer.Start(ctx, "DeleteCurve-Service")
+	defer span.End()
+
+	if !middleware.IsAdmin(ctx) {
+		return nil, middleware.ErrForbidden
+	}
+	if brand == models.DefaultCurveBrand {
+		return nil, errors.New("the default depreciation curve cannot be deleted")
+	}
+
+	deletedCurve, err := s.store.DeleteCurve(ctx, brand)
+	if err != nil {
+		return nil, err
+	}
+	return &deletedCurve, nil
+}
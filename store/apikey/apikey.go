@@ -0,0 +1,149 @@
+package apikey
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/gloonch/CarZone/models"
+	"github.com/lib/pq"
+	"go.opentelemetry.io/otel"
+)
+
+// lastUsedResolution limits how often using a key writes its last-used
+// timestamp.
+const lastUsedResolution = time.Minute
+
+type APIKeyStore struct {
+	db *sql.DB
+}
+
+func NewAPIKeyStore(db *sql.DB) *APIKeyStore {
+	return &APIKeyStore{
+		db: db,
+	}
+}
+
+const apiKeyColumns = `id, name, owner, prefix, key_hash, scopes, expires_at, created_by, created_at, last_used_at, revoked_at`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanAPIKey(row rowScanner) (models.APIKey, error) {
+	var key models.APIKey
+	err := row.Scan(
+		&key.ID,
+		&key.Name,
+		&key.Owner,
+		&key.Prefix,
+		&key.KeyHash,
+		pq.Array(&key.Scopes),
+		&key.ExpiresAt,
+		&key.CreatedBy,
+		&key.CreatedAt,
+		&key.LastUsedAt,
+		&key.RevokedAt,
+	)
+	return key, err
+}
+
+// GetAPIKeys lists the keys owned by users of a tenant.
+func (s APIKeyStore) GetAPIKeys(ctx context.Context, tenantID string) ([]models.APIKey, error) {
+	tracer := otel.Tracer("apikey-store")
+	ctx, span := tracer.Start(ctx, "GetAPIKeys-Store")
+	defer span.End()
+
+	rows, err := s.db.QueryContext(ctx, `SELECT `+apiKeyColumns+` FROM api_key
+			WHERE owner IN (SELECT username FROM users WHERE tenant_id = $1) ORDER BY created_at DESC`, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []models.APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (s APIKeyStore) CreateAPIKey(ctx context.Context, key models.APIKey) (models.APIKey, error) {
+	tracer := otel.Tracer("apikey-store")
+	ctx, span := tracer.Start(ctx, "CreateAPIKey-Store")
+	defer span.End()
+
+	row := s.db.QueryRowContext(ctx,
+		`INSERT INTO api_key (id, name, owner, prefix, key_hash, scopes, expires_at, created_by, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			RETURNING `+apiKeyColumns,
+		key.ID, key.Name, key.Owner, key.Prefix, key.KeyHash, pq.Array(key.Scopes), key.ExpiresAt, key.CreatedBy, key.CreatedAt)
+	createdKey, err := scanAPIKey(row)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23503" {
+			return createdKey, models.ErrUserNotFound
+		}
+		return createdKey, err
+	}
+	return createdKey, nil
+}
+
+// RevokeAPIKey revokes a key owned by a user of the tenant; it stays listed
+// so its use can be traced.
+func (s APIKeyStore) RevokeAPIKey(ctx context.Context, id string, tenantID string, now time.Time) (models.APIKey, error) {
+	tracer := otel.Tracer("apikey-store")
+	ctx, span := tracer.Start(ctx, "RevokeAPIKey-Store")
+	defer span.End()
+
+	row := s.db.QueryRowContext(ctx,
+		`UPDATE api_key SET revoked_at = COALESCE(revoked_at, $2)
+			WHERE id = $1 AND owner IN (SELECT username FROM users WHERE tenant_id = $3)
+			RETURNING `+apiKeyColumns,
+		id, now, tenantID)
+	key, err := scanAPIKey(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return key, models.ErrAPIKeyNotFound
+		}
+		return key, err
+	}
+	return key, nil
+}
+
+// UseAPIKey returns the valid key with the given hash and records that it
+// was used. Unknown, expired and revoked keys give models.ErrInvalidAPIKey.
+func (s APIKeyStore) UseAPIKey(ctx context.Context, keyHash string, now time.Time) (models.APIKey, error) {
+	tracer := otel.Tracer("apikey-store")
+	ctx, span := tracer.Start(ctx, "UseAPIKey-Store")
+	defer span.End()
+
+	row := s.db.QueryRowContext(ctx,
+		`SELECT `+apiKeyColumns+` FROM api_key
+			WHERE key_hash = $1 AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > $2)`,
+		keyHash, now)
+	key, err := scanAPIKey(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return key, models.ErrInvalidAPIKey
+		}
+		return key, err
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= lastUsedResolution {
+		_, err = s.db.ExecContext(ctx, `UPDATE api_key SET last_used_at = $2 WHERE id = $1`, key.ID, now)
+		if err != nil {
+			return key, err
+		}
+		key.LastUsedAt = &now
+	}
+	return key, nil
+}
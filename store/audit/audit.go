@@ -0,0 +1,67 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/gloonch/CarZone/models"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+)
+
+type AuditStore struct {
+	db *sql.DB
+}
+
+func NewAuditStore(db *sql.DB) *AuditStore {
+	return &AuditStore{
+		db: db,
+	}
+}
+
+func (s AuditStore) RecordDenial(ctx context.Context, denial models.AccessDenial) error {
+	tracer := otel.Tracer("audit-store")
+	ctx, span := tracer.Start(ctx, "RecordDenial-Store")
+	defer span.End()
+
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO access_denial (id, username, role, required_role, method, path, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		uuid.New(), denial.Username, denial.Role, denial.RequiredRole, denial.Method, denial.Path, denial.CreatedAt)
+	return err
+}
+
+// GetDenials returns the most recent denials first, optionally only those of
+// one user.
+func (s AuditStore) GetDenials(ctx context.Context, username string, limit int) ([]models.AccessDenial, error) {
+	tracer := otel.Tracer("audit-store")
+	ctx, span := tracer.Start(ctx, "GetDenials-Store")
+	defer span.End()
+
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT id, username, role, required_role, method, path, created_at
+			FROM access_denial
+			WHERE $1 = '' OR username = $1
+			ORDER BY created_at DESC
+			LIMIT $2`,
+		username, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	denials := []models.AccessDenial{}
+	for rows.Next() {
+		var denial models.AccessDenial
+		err := rows.Scan(&denial.ID, &denial.Username, &denial.Role, &denial.RequiredRole,
+			&denial.Method, &denial.Path, &denial.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		denials = append(denials, denial)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return denials, nil
+}
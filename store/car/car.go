@@ -4,10 +4,15 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"math"
+	"strings"
 	"time"
 
 	"github.com/gloonch/CarZone/models"
+	"github.com/gloonch/CarZone/store"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"go.opentelemetry.io/otel"
 )
 
@@ -21,56 +26,123 @@ func NewStore(db *sql.DB) Store {
 	}
 }
 
-func (s Store) GetCarByID(ctx context.Context, id string) (models.Car, error) {
-	tracer := otel.Tracer("car-store")
-	ctx, span := tracer.Start(ctx, "GetCarByID-Store")
-	defer span.End()
+const carColumns = `id, name, year, brand, fuel_type, engine_id, price, currency,
+	mileage, condition, previous_owners, damage_notes, attributes, tags, location, created_by, updated_by,
+	created_at, updated_at`
 
-	var car models.Car
+const carWithEngineColumns = `c.id, c.name, c.year, c.brand, c.fuel_type, c.engine_id, c.price, c.currency,
+	c.mileage, c.condition, c.previous_owners, c.damage_notes, c.attributes, c.tags, c.location, c.created_by, c.updated_by,
+	c.created_at, c.updated_at,
+	e.id, e.engine_type, e.displacement, e.no_of_cylinders, e.car_range, e.power_kw, e.torque_nm,
+	e.aspiration, e.transmission, e.battery_capacity_kwh, e.charging_power_kw`
 
-	query := `SELECT c.id, c.name, c.year, c.brand, c.fuel_type, c.engine_id, c.price, c.created_at, c.updated_at,
-				e.id, e.displacement, e.no_of_cylinders, e.car_range FROM car c LEFT JOIN engine e ON c.engine_id = e.id WHERE c.id = $1`
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
 
-	row := s.db.QueryRowContext(ctx, query, id)
-	err := row.Scan(
+// carFields lists the scan destinations matching carColumns.
+func carFields(car *models.Car) []interface{} {
+	return []interface{}{
 		&car.ID,
 		&car.Name,
 		&car.Year,
 		&car.Brand,
 		&car.FuelType,
 		&car.Engine.EngineID,
-		&car.Price,
+		&car.Price.Amount,
+		&car.Price.Currency,
+		&car.Mileage,
+		&car.Condition,
+		&car.PreviousOwners,
+		&car.DamageNotes,
+		&car.Attributes,
+		pq.Array(&car.Tags),
+		&car.Location,
+		&car.CreatedBy,
+		&car.UpdatedBy,
 		&car.CreatedAt,
 		&car.UpdatedAt,
+	}
+}
+
+func scanCar(row rowScanner, car *models.Car) error {
+	return row.Scan(carFields(car)...)
+}
+
+func scanCarWithEngine(row rowScanner, car *models.Car) error {
+	err := row.Scan(append(carFields(car),
 		&car.Engine.EngineID,
+		&car.Engine.EngineType,
 		&car.Engine.Displacement,
 		&car.Engine.NoOfCylinders,
 		&car.Engine.CarRange,
-	)
+		&car.Engine.PowerKW,
+		&car.Engine.TorqueNm,
+		&car.Engine.Aspiration,
+		&car.Engine.Transmission,
+		&car.Engine.BatteryCapacityKWh,
+		&car.Engine.ChargingPowerKW,
+	)...)
+	if err != nil {
+		return err
+	}
+	car.Engine.DerivePowerHP()
+	return nil
+}
+
+func (s Store) GetCarByID(ctx context.Context, id string) (models.Car, error) {
+	tracer := otel.Tracer("car-store")
+	ctx, span := tracer.Start(ctx, "GetCarByID-Store")
+	defer span.End()
+
+	var car models.Car
+
+	tx, tenant, err := store.BeginTenantTx(ctx, s.db)
+	if err != nil {
+		return car, err
+	}
+	defer tx.Rollback()
+
+	query := `SELECT ` + carWithEngineColumns + ` FROM car c LEFT JOIN engine e ON c.engine_id = e.id
+				WHERE c.id = $1 AND c.tenant_id = $2`
+
+	row := tx.QueryRowContext(ctx, query, id, tenant)
+	err = scanCarWithEngine(row, &car)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return car, errors.New("Car not found")
+			return car, models.ErrCarNotFound
 		}
 		return car, err
 	}
-	return car, nil
+
+	cars := []models.Car{car}
+	if err := loadDetails(ctx, tx, cars); err != nil {
+		return car, err
+	}
+	return cars[0], nil
 }
 
-func (s Store) GetCarByBrand(ctx context.Context, brand string, isEngine bool) ([]models.Car, error) {
+func (s Store) GetCarByBrand(ctx context.Context, query models.CarQuery) ([]models.Car, error) {
 	tracer := otel.Tracer("car-store")
 	ctx, span := tracer.Start(ctx, "GetCarByBrand-Store")
 	defer span.End()
 
 	var cars []models.Car
 
-	var query string
-	if isEngine {
-		query = `SELECT c.id, c.name, c.year, c.brand, c.fuel_type, c.engine_id, c.price, c.created_at, c.updated_at,
-					e.id, e.displacement, e.no_of_cylinders, e.car_range FROM car c Left JOIN engine e ON c.engine_id = e.id WHERE c.brand = $1`
+	tx, tenant, err := store.BeginTenantTx(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var sqlQuery string
+	if query.IsEngine {
+		sqlQuery = `SELECT ` + carWithEngineColumns + ` FROM car c LEFT JOIN engine e ON c.engine_id = e.id`
 	} else {
-		query = `SELECT id, name, year, brand, fuel_type, engine_id, price, created_at, updated_at FROM car WHERE brand = $1`
+		sqlQuery = `SELECT ` + carColumns + ` FROM car c`
 	}
-	rows, err := s.db.QueryContext(ctx, query, brand)
+	where, args := carFilters(query, tenant)
+	rows, err := tx.QueryContext(ctx, sqlQuery+` WHERE `+where, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -78,42 +150,13 @@ func (s Store) GetCarByBrand(ctx context.Context, brand string, isEngine bool) (
 	defer rows.Close()
 	for rows.Next() {
 		var car models.Car
-		if isEngine {
-			var engine models.Engine
-			err := rows.Scan(
-				&car.ID,
-				&car.Name,
-				&car.Year,
-				&car.Brand,
-				&car.FuelType,
-				&car.Engine.EngineID,
-				&car.Price,
-				&car.CreatedAt,
-				&car.UpdatedAt,
-				&car.Engine.EngineID,
-				&car.Engine.Displacement,
-				&car.Engine.NoOfCylinders,
-				&car.Engine.CarRange,
-			)
-			if err != nil {
-				return nil, err
-			}
-			car.Engine = engine
+		if query.IsEngine {
+			err = scanCarWithEngine(rows, &car)
 		} else {
-			err = rows.Scan(
-				&car.ID,
-				&car.Name,
-				&car.Year,
-				&car.Brand,
-				&car.FuelType,
-				&car.Engine.EngineID,
-				&car.Price,
-				&car.CreatedAt,
-				&car.UpdatedAt,
-			)
-			if err != nil {
-				return nil, err
-			}
+			err = scanCar(rows, &car)
+		}
+		if err != nil {
+			return nil, err
 		}
 		cars = append(cars, car)
 	}
@@ -121,42 +164,289 @@ func (s Store) GetCarByBrand(ctx context.Context, brand string, isEngine bool) (
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
+	if err := loadDetails(ctx, tx, cars); err != nil {
+		return nil, err
+	}
 	return cars, nil
 }
 
-func (s Store) CreateCar(ctx context.Context, carReq *models.CarRequest) (models.Car, error) {
+// carFilters builds the WHERE clause of a listing of the tenant's cars from
+// the query filters. Both listing variants alias the car table as c.
+func carFilters(query models.CarQuery, tenant string) (string, []interface{}) {
+	conditions := []string{"c.tenant_id = $1", "c.brand = $2"}
+	args := []interface{}{tenant, query.Brand}
+	add := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if query.MinMileage != nil {
+		add("c.mileage >= $%d", *query.MinMileage)
+	}
+	if query.MaxMileage != nil {
+		add("c.mileage <= $%d", *query.MaxMileage)
+	}
+	if query.Condition != "" {
+		add("c.condition = $%d", query.Condition)
+	}
+	if query.MaxPreviousOwners != nil {
+		add("c.previous_owners <= $%d", *query.MaxPreviousOwners)
+	}
+	if query.Damaged != nil {
+		if *query.Damaged {
+			conditions = append(conditions, "c.damage_notes <> ''")
+		} else {
+			conditions = append(conditions, "c.damage_notes = ''")
+		}
+	}
+	if len(query.Attributes) > 0 {
+		add("c.attributes @> $%d::jsonb", query.Attributes)
+	}
+	if len(query.Tags) > 0 {
+		add("c.tags @> $%d::text[]", pq.Array(query.Tags))
+	}
+	return strings.Join(conditions, " AND "), args
+}
+
+// loadDetails attaches the media and rating of every car in cars.
+func loadDetails(ctx context.Context, tx *sql.Tx, cars []models.Car) error {
+	if err := loadMedia(ctx, tx, cars); err != nil {
+		return err
+	}
+	return loadRatings(ctx, tx, cars)
+}
+
+// tagArray passes tags as a text[] value; the column does not take NULL.
+func tagArray(tags []string) interface{} {
+	if tags == nil {
+		tags = []string{}
+	}
+	return pq.Array(tags)
+}
+
+// GetTags lists every tag in use by the tenant's cars with the number of
+// cars carrying it.
+func (s Store) GetTags(ctx context.Context) ([]models.Tag, error) {
 	tracer := otel.Tracer("car-store")
-	ctx, span := tracer.Start(ctx, "CreateCar-Store")
+	ctx, span := tracer.Start(ctx, "GetTags-Store")
 	defer span.End()
 
-	var createdCar models.Car
-	var engineID uuid.UUID
+	tx, tenant, err := store.BeginTenantTx(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
 
-	err := s.db.QueryRowContext(ctx, "SELECT id FROM engine WHERE id = $1", carReq.Engine.EngineID).Scan(&engineID)
+	rows, err := tx.QueryContext(ctx,
+		`SELECT tag, COUNT(*) FROM car c, unnest(c.tags) AS tag
+			WHERE c.tenant_id = $1
+			GROUP BY tag ORDER BY tag`, tenant)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return createdCar, errors.New("engine_id does not exist")
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := []models.Tag{}
+	for rows.Next() {
+		var tag models.Tag
+		if err := rows.Scan(&tag.Name, &tag.Count); err != nil {
+			return nil, err
 		}
-		return createdCar, err
+		tags = append(tags, tag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// loadRatings fills in the rating of every car in cars from the approved
+// reviews of the car itself and of its model.
+func loadRatings(ctx context.Context, tx *sql.Tx, cars []models.Car) error {
+	if len(cars) == 0 {
+		return nil
 	}
 
+	ids := make([]string, len(cars))
+	index := make(map[uuid.UUID]int, len(cars))
+	for i, car := range cars {
+		ids[i] = car.ID.String()
+		index[car.ID] = i
+	}
+
+	rows, err := tx.QueryContext(ctx,
+		`SELECT c.id, AVG(r.rating), COUNT(r.id) FROM car c
+			JOIN review r ON r.status = 'approved' AND r.tenant_id = c.tenant_id
+				AND (r.car_id = c.id OR (r.car_id IS NULL AND r.brand = c.brand AND r.model = c.name))
+			WHERE c.id = ANY($1::uuid[])
+			GROUP BY c.id`, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var carID uuid.UUID
+		var rating models.Rating
+		if err := rows.Scan(&carID, &rating.Average, &rating.Count); err != nil {
+			return err
+		}
+		rating.Average = math.Round(rating.Average*100) / 100
+		cars[index[carID]].Rating = rating
+	}
+	return rows.Err()
+}
+
+// loadMedia attaches the media of every car in cars using a single query.
+func loadMedia(ctx context.Context, tx *sql.Tx, cars []models.Car) error {
+	if len(cars) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(cars))
+	index := make(map[uuid.UUID]int, len(cars))
+	for i, car := range cars {
+		ids[i] = car.ID.String()
+		index[car.ID] = i
+	}
+
+	rows, err := tx.QueryContext(ctx,
+		`SELECT id, car_id, kind, file_name, content_type, size_bytes, blob_key, COALESCE(thumbnail_key, ''), created_at
+			FROM car_media WHERE car_id = ANY($1::uuid[]) ORDER BY created_at`, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var media models.CarMedia
+		err := rows.Scan(
+			&media.ID,
+			&media.CarID,
+			&media.Kind,
+			&media.FileName,
+			&media.ContentType,
+			&media.Size,
+			&media.BlobKey,
+			&media.ThumbnailKey,
+			&media.CreatedAt,
+		)
+		if err != nil {
+			return err
+		}
+		media.SetURLs()
+		i := index[media.CarID]
+		cars[i].Images = append(cars[i].Images, media)
+	}
+	return rows.Err()
+}
+
+// GetCarsByIDs loads several cars with their engines in one query. Cars are
+// returned in no particular order and missing IDs are simply absent.
+func (s Store) GetCarsByIDs(ctx context.Context, ids []string) ([]models.Car, error) {
+	tracer := otel.Tracer("car-store")
+	ctx, span := tracer.Start(ctx, "GetCarsByIDs-Store")
+	defer span.End()
+
+	tx, tenant, err := store.BeginTenantTx(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	query := `SELECT ` + carWithEngineColumns + ` FROM car c LEFT JOIN engine e ON c.engine_id = e.id
+				WHERE c.id = ANY($1::uuid[]) AND c.tenant_id = $2`
+	rows, err := tx.QueryContext(ctx, query, pq.Array(ids), tenant)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var cars []models.Car
+	for rows.Next() {
+		var car models.Car
+		if err := scanCarWithEngine(rows, &car); err != nil {
+			return nil, err
+		}
+		cars = append(cars, car)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if err := loadDetails(ctx, tx, cars); err != nil {
+		return nil, err
+	}
+	return cars, nil
+}
+
+// GetAllCars loads every car of the tenant with its engine, without media,
+// for in-process ranking.
+func (s Store) GetAllCars(ctx context.Context) ([]models.Car, error) {
+	tracer := otel.Tracer("car-store")
+	ctx, span := tracer.Start(ctx, "GetAllCars-Store")
+	defer span.End()
+
+	tx, tenant, err := store.BeginTenantTx(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `SELECT `+carWithEngineColumns+` FROM car c LEFT JOIN engine e ON c.engine_id = e.id
+		WHERE c.tenant_id = $1`, tenant)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var cars []models.Car
+	for rows.Next() {
+		var car models.Car
+		if err := scanCarWithEngine(rows, &car); err != nil {
+			return nil, err
+		}
+		cars = append(cars, car)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return cars, nil
+}
+
+// CreateCar lists a car owned by username.
+func (s Store) CreateCar(ctx context.Context, carReq *models.CarRequest, username string) (models.Car, error) {
+	tracer := otel.Tracer("car-store")
+	ctx, span := tracer.Start(ctx, "CreateCar-Store")
+	defer span.End()
+
+	var createdCar models.Car
+
 	carID := uuid.New()
 	createdAt := time.Now()
 
 	newCar := models.Car{
-		ID:        carID,
-		Name:      carReq.Name,
-		Year:      carReq.Year,
-		Brand:     carReq.Brand,
-		FuelType:  carReq.FuelType,
-		Engine:    carReq.Engine,
-		Price:     carReq.Price,
-		CreatedAt: createdAt,
-		UpdatedAt: createdAt,
+		ID:             carID,
+		Name:           carReq.Name,
+		Year:           carReq.Year,
+		Brand:          carReq.Brand,
+		FuelType:       carReq.FuelType,
+		Engine:         carReq.Engine,
+		Price:          carReq.Price,
+		Mileage:        carReq.Mileage,
+		Condition:      carReq.Condition,
+		PreviousOwners: carReq.PreviousOwners,
+		DamageNotes:    carReq.DamageNotes,
+		Attributes:     carReq.Attributes,
+		Tags:           carReq.Tags,
+		Location:       carReq.Location,
+		CreatedBy:      username,
+		UpdatedBy:      username,
+		CreatedAt:      createdAt,
+		UpdatedAt:      createdAt,
 	}
 
 	// Begin the transaction
-	tx, err := s.db.BeginTx(ctx, nil)
+	tx, tenant, err := store.BeginTenantTx(ctx, s.db)
 	if err != nil {
 		return createdCar, err
 	}
@@ -168,31 +458,37 @@ func (s Store) CreateCar(ctx context.Context, carReq *models.CarRequest) (models
 		err = tx.Commit()
 	}()
 
-	query := `INSERT INTO car (id, name, year, brand, fuel_type, engine_id, price, created_at, updated_at)
-				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
-				RETURNING id, name, year, brand, fuel_type, engine_id, price, created_at, updated_at`
+	err = checkEngine(ctx, tx, carReq.Engine.EngineID, tenant)
+	if err != nil {
+		return createdCar, err
+	}
+
+	query := `INSERT INTO car (` + carColumns + `, tenant_id)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20)
+				RETURNING ` + carColumns
 
-	err = tx.QueryRowContext(ctx, query,
+	err = scanCar(tx.QueryRowContext(ctx, query,
 		newCar.ID,
 		newCar.Name,
 		newCar.Year,
 		newCar.Brand,
 		newCar.FuelType,
 		newCar.Engine.EngineID,
-		newCar.Price,
+		newCar.Price.Amount,
+		newCar.Price.Currency,
+		newCar.Mileage,
+		newCar.Condition,
+		newCar.PreviousOwners,
+		newCar.DamageNotes,
+		newCar.Attributes,
+		tagArray(newCar.Tags),
+		newCar.Location,
+		newCar.CreatedBy,
+		newCar.UpdatedBy,
 		newCar.CreatedAt,
 		newCar.UpdatedAt,
-	).Scan(
-		&createdCar.ID,
-		&createdCar.Name,
-		&createdCar.Year,
-		&createdCar.Brand,
-		&createdCar.FuelType,
-		&createdCar.Engine.EngineID,
-		&createdCar.Price,
-		&createdCar.CreatedAt,
-		&createdCar.UpdatedAt,
-	)
+		tenant,
+	), &createdCar)
 	if err != nil {
 		return createdCar, err
 	}
@@ -200,7 +496,21 @@ func (s Store) CreateCar(ctx context.Context, carReq *models.CarRequest) (models
 	return createdCar, nil
 }
 
-func (s Store) UpdateCar(ctx context.Context, id string, carReq *models.CarRequest) (models.Car, error) {
+// checkEngine makes sure a car is built on an engine of its own tenant.
+func checkEngine(ctx context.Context, tx *sql.Tx, engineID uuid.UUID, tenant string) error {
+	var id uuid.UUID
+	err := tx.QueryRowContext(ctx, "SELECT id FROM engine WHERE id = $1 AND tenant_id = $2", engineID, tenant).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return errors.New("engine_id does not exist")
+	}
+	return err
+}
+
+// UpdateCar replaces the car's details and records username as the last
+// editor. The odometer reading may only go down when carReq carries an
+// override, in which case the correction is written to odometer_audit under
+// username in the same transaction.
+func (s Store) UpdateCar(ctx context.Context, id string, carReq *models.CarRequest, username string) (models.Car, error) {
 
 	tracer := otel.Tracer("car-store")
 	ctx, span := tracer.Start(ctx, "UpdateCar-Store")
@@ -209,7 +519,7 @@ func (s Store) UpdateCar(ctx context.Context, id string, carReq *models.CarReque
 	var updatedCar models.Car
 
 	// Begin the transaction
-	tx, err := s.db.BeginTx(ctx, nil)
+	tx, tenant, err := store.BeginTenantTx(ctx, s.db)
 	if err != nil {
 		return updatedCar, err
 	}
@@ -221,31 +531,59 @@ func (s Store) UpdateCar(ctx context.Context, id string, carReq *models.CarReque
 		err = tx.Commit()
 	}()
 
+	var currentMileage int64
+	err = tx.QueryRowContext(ctx, "SELECT mileage FROM car WHERE id = $1 AND tenant_id = $2 FOR UPDATE", id, tenant).Scan(&currentMileage)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = errors.New("car does not exist")
+		}
+		return updatedCar, err
+	}
+	err = checkEngine(ctx, tx, carReq.Engine.EngineID, tenant)
+	if err != nil {
+		return updatedCar, err
+	}
+	if carReq.Mileage < currentMileage {
+		if carReq.OdometerOverride == nil {
+			err = models.ErrOdometerDecrease
+			return updatedCar, err
+		}
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO odometer_audit (id, car_id, previous_mileage, new_mileage, reason, username, created_at)
+				VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+			uuid.New(), id, currentMileage, carReq.Mileage, carReq.OdometerOverride.Reason, username, time.Now())
+		if err != nil {
+			return updatedCar, err
+		}
+	}
+
 	query := `UPDATE car
-				SET name = $2, year = $3, fuel_type = $4, engine_id = $6, price = $7, updated_at = $8
-				WHERE id = $1
-				RETURNING id, name, year, brand, fuel_type, engine_id, price, created_at, updated_at`
+				SET name = $2, year = $3, brand = $4, fuel_type = $5, engine_id = $6, price = $7, currency = $8,
+					mileage = $9, condition = $10, previous_owners = $11, damage_notes = $12, attributes = $13, updated_at = $14,
+					location = $15, updated_by = $16, tags = $18
+				WHERE id = $1 AND tenant_id = $17
+				RETURNING ` + carColumns
 
-	err = tx.QueryRowContext(ctx, query,
+	err = scanCar(tx.QueryRowContext(ctx, query,
 		id,
 		carReq.Name,
 		carReq.Year,
 		carReq.Brand,
 		carReq.FuelType,
 		carReq.Engine.EngineID,
-		carReq.Price,
+		carReq.Price.Amount,
+		carReq.Price.Currency,
+		carReq.Mileage,
+		carReq.Condition,
+		carReq.PreviousOwners,
+		carReq.DamageNotes,
+		carReq.Attributes,
 		time.Now(),
-	).Scan(
-		&updatedCar.ID,
-		&updatedCar.Name,
-		&updatedCar.Year,
-		&updatedCar.Brand,
-		&updatedCar.FuelType,
-		&updatedCar.Engine.EngineID,
-		&updatedCar.Price,
-		&updatedCar.CreatedAt,
-		&updatedCar.UpdatedAt,
-	)
+		carReq.Location,
+		username,
+		tenant,
+		tagArray(carReq.Tags),
+	), &updatedCar)
 	if err != nil {
 		return updatedCar, err
 	}
@@ -254,6 +592,51 @@ func (s Store) UpdateCar(ctx context.Context, id string, carReq *models.CarReque
 
 }
 
+// GetOdometerAudit lists the overridden odometer corrections of one of the
+// tenant's cars, newest first.
+func (s Store) GetOdometerAudit(ctx context.Context, carID string) ([]models.OdometerAudit, error) {
+	tracer := otel.Tracer("car-store")
+	ctx, span := tracer.Start(ctx, "GetOdometerAudit-Store")
+	defer span.End()
+
+	tx, tenant, err := store.BeginTenantTx(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx,
+		`SELECT a.id, a.car_id, a.previous_mileage, a.new_mileage, a.reason, a.username, a.created_at
+			FROM odometer_audit a JOIN car c ON c.id = a.car_id
+			WHERE a.car_id = $1 AND c.tenant_id = $2 ORDER BY a.created_at DESC`, carID, tenant)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	audits := []models.OdometerAudit{}
+	for rows.Next() {
+		var audit models.OdometerAudit
+		err := rows.Scan(
+			&audit.ID,
+			&audit.CarID,
+			&audit.PreviousMileage,
+			&audit.NewMileage,
+			&audit.Reason,
+			&audit.Username,
+			&audit.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		audits = append(audits, audit)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return audits, nil
+}
+
 func (s Store) DeleteCar(ctx context.Context, id string) (models.Car, error) {
 
 	tracer := otel.Tracer("car-store")
@@ -262,7 +645,7 @@ func (s Store) DeleteCar(ctx context.Context, id string) (models.Car, error) {
 
 	var deletedCar models.Car
 
-	tx, err := s.db.BeginTx(ctx, nil)
+	tx, tenant, err := store.BeginTenantTx(ctx, s.db)
 	if err != nil {
 		return deletedCar, err
 	}
@@ -275,23 +658,14 @@ func (s Store) DeleteCar(ctx context.Context, id string) (models.Car, error) {
 		err = tx.Commit()
 	}()
 
-	err = tx.QueryRowContext(ctx, "SELECT id, name, year, brand, fuel_type, engine_id, price, created_at, updated_at FROM car WHERE id = $1", id).Scan(
-		&deletedCar.ID,
-		&deletedCar.Name,
-		&deletedCar.Year,
-		&deletedCar.Brand,
-		&deletedCar.FuelType,
-		&deletedCar.Engine.EngineID,
-		&deletedCar.Price,
-		&deletedCar.CreatedAt,
-		&deletedCar.UpdatedAt)
+	err = scanCar(tx.QueryRowContext(ctx, "SELECT "+carColumns+" FROM car WHERE id = $1 AND tenant_id = $2", id, tenant), &deletedCar)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return models.Car{}, errors.New("car does not exist")
 		}
 		return models.Car{}, err
 	}
-	result, err := tx.ExecContext(ctx, "DELETE FROM car WHERE id = $1", id)
+	result, err := tx.ExecContext(ctx, "DELETE FROM car WHERE id = $1 AND tenant_id = $2", id, tenant)
 	if err != nil {
 		return models.Car{}, err
 	}
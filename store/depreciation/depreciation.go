@@ -0,0 +1,118 @@
+package depreciation
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/gloonch/CarZone/models"
+	"github.com/lib/pq"
+	"go.opentelemetry.io/otel"
+)
+
+type DepreciationStore struct {
+	db *sql.DB
+}
+
+func NewDepreciationStore(db *sql.DB) *DepreciationStore {
+	return &DepreciationStore{
+		db: db,
+	}
+}
+
+const curveColumns = `brand, yearly_rates, annual_mileage, mileage_rate, updated_at`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanCurve(row rowScanner) (models.DepreciationCurve, error) {
+	var curve models.DepreciationCurve
+	err := row.Scan(
+		&curve.Brand,
+		pq.Array(&curve.YearlyRates),
+		&curve.AnnualMileage,
+		&curve.MileageRate,
+		&curve.UpdatedAt,
+	)
+	return curve, err
+}
+
+func (s DepreciationStore) GetCurves(ctx context.Context) ([]models.DepreciationCurve, error) {
+	tracer := otel.Tracer("depreciation-store")
+	ctx, span := tracer.Start(ctx, "GetCurves-Store")
+	defer span.End()
+
+	rows, err := s.db.QueryContext(ctx, `SELECT `+curveColumns+` FROM depreciation_curve ORDER BY brand`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	curves := []models.DepreciationCurve{}
+	for rows.Next() {
+		curve, err := scanCurve(rows)
+		if err != nil {
+			return nil, err
+		}
+		curves = append(curves, curve)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return curves, nil
+}
+
+// CurveForBrand returns the curve of brand, falling back to the default
+// curve when the brand has none.
+func (s DepreciationStore) CurveForBrand(ctx context.Context, brand string) (models.DepreciationCurve, error) {
+	tracer := otel.Tracer("depreciation-store")
+	ctx, span := tracer.Start(ctx, "CurveForBrand-Store")
+	defer span.End()
+
+	row := s.db.QueryRowContext(ctx,
+		`SELECT `+curveColumns+` FROM depreciation_curve WHERE brand IN ($1, $2)
+			ORDER BY brand = $2 LIMIT 1`, brand, models.DefaultCurveBrand)
+	curve, err := scanCurve(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return curve, errors.New("no depreciation curve for " + brand)
+		}
+		return curve, err
+	}
+	return curve, nil
+}
+
+// SaveCurve creates or replaces the curve of brand.
+func (s DepreciationStore) SaveCurve(ctx context.Context, brand string, curveReq *models.DepreciationCurveRequest) (models.DepreciationCurve, error) {
+	tracer := otel.Tracer("depreciation-store")
+	ctx, span := tracer.Start(ctx, "SaveCurve-Store")
+	defer span.End()
+
+	row := s.db.QueryRowContext(ctx,
+		`INSERT INTO depreciation_curve (brand, yearly_rates, annual_mileage, mileage_rate, updated_at)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (brand) DO UPDATE SET yearly_rates = EXCLUDED.yearly_rates,
+				annual_mileage = EXCLUDED.annual_mileage, mileage_rate = EXCLUDED.mileage_rate,
+				updated_at = EXCLUDED.updated_at
+			RETURNING `+curveColumns,
+		brand, pq.Array(curveReq.YearlyRates), curveReq.AnnualMileage, curveReq.MileageRate, time.Now())
+	return scanCurve(row)
+}
+
+func (s DepreciationStore) DeleteCurve(ctx context.Context, brand string) (models.DepreciationCurve, error) {
+	tracer := otel.Tracer("depreciation-store")
+	ctx, span := tracer.Start(ctx, "DeleteCurve-Store")
+	defer span.End()
+
+	row := s.db.QueryRowContext(ctx, `DELETE FROM depreciation_curve WHERE brand = $1 RETURNING `+curveColumns, brand)
+	curve, err := scanCurve(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return curve, errors.New("depreciation curve does not exist")
+		}
+		return curve, err
+	}
+	return curve, nil
+}
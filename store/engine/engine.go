@@ -7,6 +7,7 @@ import (
 	"fmt"
 
 	"github.com/gloonch/CarZone/models"
+	"github.com/gloonch/CarZone/store"
 	"github.com/google/uuid"
 	"go.opentelemetry.io/otel"
 )
@@ -21,6 +22,30 @@ func NewEngineStore(db *sql.DB) *EngineStore {
 	}
 }
 
+const engineColumns = `id, engine_type, displacement, no_of_cylinders, car_range, power_kw, torque_nm,
+	aspiration, transmission, battery_capacity_kwh, charging_power_kw`
+
+func scanEngine(row *sql.Row, engine *models.Engine) error {
+	err := row.Scan(
+		&engine.EngineID,
+		&engine.EngineType,
+		&engine.Displacement,
+		&engine.NoOfCylinders,
+		&engine.CarRange,
+		&engine.PowerKW,
+		&engine.TorqueNm,
+		&engine.Aspiration,
+		&engine.Transmission,
+		&engine.BatteryCapacityKWh,
+		&engine.ChargingPowerKW,
+	)
+	if err != nil {
+		return err
+	}
+	engine.DerivePowerHP()
+	return nil
+}
+
 func (e EngineStore) EngineByID(ctx context.Context, id string) (models.Engine, error) {
 	tracer := otel.Tracer("engine-store")
 	ctx, span := tracer.Start(ctx, "EngineByID-Store")
@@ -28,7 +53,7 @@ func (e EngineStore) EngineByID(ctx context.Context, id string) (models.Engine,
 
 	var engine models.Engine
 
-	tx, err := e.db.BeginTx(ctx, nil)
+	tx, tenant, err := store.BeginTenantTx(ctx, e.db)
 	if err != nil {
 		return engine, err
 	}
@@ -46,9 +71,7 @@ func (e EngineStore) EngineByID(ctx context.Context, id string) (models.Engine,
 		}
 	}()
 
-	tx.QueryRowContext(ctx, "SELECT id, displacement, no_of_cylinders, car_range FROM engine WHERE id=$1", id).Scan(
-		&engine.EngineID, &engine.Displacement, &engine.NoOfCylinders, &engine.CarRange,
-	)
+	err = scanEngine(tx.QueryRowContext(ctx, "SELECT "+engineColumns+" FROM engine WHERE id=$1 AND tenant_id=$2", id, tenant), &engine)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return engine, errors.New("Engine does not exist")
@@ -64,7 +87,7 @@ func (e EngineStore) CreateEngine(ctx context.Context, engineReq *models.EngineR
 	ctx, span := tracer.Start(ctx, "CreateEngine-Store")
 	defer span.End()
 
-	tx, err := e.db.BeginTx(ctx, nil)
+	tx, tenant, err := store.BeginTenantTx(ctx, e.db)
 	if err != nil {
 		return models.Engine{}, err
 	}
@@ -85,19 +108,17 @@ func (e EngineStore) CreateEngine(ctx context.Context, engineReq *models.EngineR
 	engineID := uuid.New()
 
 	_, err = tx.ExecContext(ctx,
-		"INSERT INTO engine ( id, displacement, no_of_cylinders, car_range ) VALUES ( $1, $2, $3, $4 )",
-		engineID, engineReq.Displacement, engineReq.NoOfCylinders, engineReq.CarRange)
+		`INSERT INTO engine ( id, engine_type, displacement, no_of_cylinders, car_range, power_kw, torque_nm,
+			aspiration, transmission, battery_capacity_kwh, charging_power_kw, tenant_id )
+			VALUES ( $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12 )`,
+		engineID, engineReq.EngineType, engineReq.Displacement, engineReq.NoOfCylinders, engineReq.CarRange,
+		engineReq.PowerKW, engineReq.TorqueNm, engineReq.Aspiration, engineReq.Transmission,
+		engineReq.BatteryCapacityKWh, engineReq.ChargingPowerKW, tenant)
 	if err != nil {
 		return models.Engine{}, err
 	}
-	engine := models.Engine{
-		EngineID:      engineID,
-		Displacement:  engineReq.Displacement,
-		NoOfCylinders: engineReq.NoOfCylinders,
-		CarRange:      engineReq.CarRange,
-	}
 
-	return engine, nil
+	return engineReq.Engine(engineID), nil
 }
 
 func (e EngineStore) UpdateEngine(ctx context.Context, id string, engineReq *models.EngineRequest) (models.Engine, error) {
@@ -111,7 +132,7 @@ func (e EngineStore) UpdateEngine(ctx context.Context, id string, engineReq *mod
 		return models.Engine{}, fmt.Errorf("Invalid engine ID: %s", id)
 	}
 
-	tx, err := e.db.BeginTx(ctx, nil)
+	tx, tenant, err := store.BeginTenantTx(ctx, e.db)
 	if err != nil {
 		return models.Engine{}, err
 	}
@@ -130,8 +151,13 @@ func (e EngineStore) UpdateEngine(ctx context.Context, id string, engineReq *mod
 	}()
 
 	results, err := tx.ExecContext(ctx,
-		"UPDATE engine SET displacement = $1, no_of_cylinders = $2, car_range = $3 WHERE id = $4",
-		engineReq.Displacement, engineReq.NoOfCylinders, engineReq.CarRange, engineID)
+		`UPDATE engine SET engine_type = $1, displacement = $2, no_of_cylinders = $3, car_range = $4, power_kw = $5,
+			torque_nm = $6, aspiration = $7, transmission = $8, battery_capacity_kwh = $9, charging_power_kw = $10,
+			updated_at = CURRENT_TIMESTAMP
+			WHERE id = $11 AND tenant_id = $12`,
+		engineReq.EngineType, engineReq.Displacement, engineReq.NoOfCylinders, engineReq.CarRange,
+		engineReq.PowerKW, engineReq.TorqueNm, engineReq.Aspiration, engineReq.Transmission,
+		engineReq.BatteryCapacityKWh, engineReq.ChargingPowerKW, engineID, tenant)
 	if err != nil {
 		return models.Engine{}, err
 	}
@@ -143,14 +169,7 @@ func (e EngineStore) UpdateEngine(ctx context.Context, id string, engineReq *mod
 		return models.Engine{}, errors.New("No rows updated")
 	}
 
-	engine := models.Engine{
-		EngineID:      engineID,
-		Displacement:  engineReq.Displacement,
-		NoOfCylinders: engineReq.NoOfCylinders,
-		CarRange:      engineReq.CarRange,
-	}
-
-	return engine, nil
+	return engineReq.Engine(engineID), nil
 }
 
 func (e EngineStore) DeleteEngine(ctx context.Context, id string) (models.Engine, error) {
@@ -161,7 +180,7 @@ func (e EngineStore) DeleteEngine(ctx context.Context, id string) (models.Engine
 
 	var engine models.Engine
 
-	tx, err := e.db.BeginTx(ctx, nil)
+	tx, tenant, err := store.BeginTenantTx(ctx, e.db)
 	if err != nil {
 		return models.Engine{}, err
 	}
@@ -179,9 +198,7 @@ func (e EngineStore) DeleteEngine(ctx context.Context, id string) (models.Engine
 		}
 	}()
 
-	err = tx.QueryRowContext(ctx, "SELECT id, displacement, no_of_cylinders, car_range FROM engine WHERE id=$1", id).Scan(
-		&engine.EngineID, &engine.Displacement, &engine.NoOfCylinders, &engine.CarRange,
-	)
+	err = scanEngine(tx.QueryRowContext(ctx, "SELECT "+engineColumns+" FROM engine WHERE id=$1 AND tenant_id=$2", id, tenant), &engine)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return engine, errors.New("Engine does not exist")
@@ -189,7 +206,7 @@ func (e EngineStore) DeleteEngine(ctx context.Context, id string) (models.Engine
 		return engine, err
 	}
 
-	result, err := tx.ExecContext(ctx, "DELETE FROM engine WHERE id=$1", id)
+	result, err := tx.ExecContext(ctx, "DELETE FROM engine WHERE id=$1 AND tenant_id=$2", id, tenant)
 	if err != nil {
 		return models.Engine{}, err
 	}
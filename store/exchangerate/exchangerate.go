@@ -0,0 +1,132 @@
+package exchangerate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gloonch/CarZone/models"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+)
+
+type ExchangeRateStore struct {
+	db *sql.DB
+}
+
+func NewExchangeRateStore(db *sql.DB) *ExchangeRateStore {
+	return &ExchangeRateStore{
+		db: db,
+	}
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanExchangeRate(row rowScanner) (models.ExchangeRate, error) {
+	var rate models.ExchangeRate
+	var rateDate time.Time
+	err := row.Scan(
+		&rate.ID,
+		&rate.BaseCurrency,
+		&rate.QuoteCurrency,
+		&rate.Rate,
+		&rateDate,
+		&rate.CreatedAt,
+	)
+	if err != nil {
+		return rate, err
+	}
+	rate.RateDate = rateDate.Format(models.DateLayout)
+	return rate, nil
+}
+
+func (s ExchangeRateStore) GetExchangeRates(ctx context.Context) ([]models.ExchangeRate, error) {
+	tracer := otel.Tracer("exchange-rate-store")
+	ctx, span := tracer.Start(ctx, "GetExchangeRates-Store")
+	defer span.End()
+
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT id, base_currency, quote_currency, rate, rate_date, created_at FROM exchange_rate
+			ORDER BY base_currency, quote_currency, rate_date DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rates []models.ExchangeRate
+	for rows.Next() {
+		rate, err := scanExchangeRate(rows)
+		if err != nil {
+			return nil, err
+		}
+		rates = append(rates, rate)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return rates, nil
+}
+
+// LatestExchangeRate returns the most recent rate for the pair that is
+// already in effect, ignoring rates entered for future dates.
+func (s ExchangeRateStore) LatestExchangeRate(ctx context.Context, base, quote string) (models.ExchangeRate, error) {
+	tracer := otel.Tracer("exchange-rate-store")
+	ctx, span := tracer.Start(ctx, "LatestExchangeRate-Store")
+	defer span.End()
+
+	row := s.db.QueryRowContext(ctx,
+		`SELECT id, base_currency, quote_currency, rate, rate_date, created_at FROM exchange_rate
+			WHERE base_currency = $1 AND quote_currency = $2 AND rate_date <= CURRENT_DATE
+			ORDER BY rate_date DESC LIMIT 1`, base, quote)
+	rate, err := scanExchangeRate(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return rate, fmt.Errorf("%w %s", models.ErrNoExchangeRate, quote)
+		}
+		return rate, err
+	}
+	return rate, nil
+}
+
+// CreateExchangeRate stores a rate for the pair and date, replacing any rate
+// already recorded for that same day.
+func (s ExchangeRateStore) CreateExchangeRate(ctx context.Context, rateReq *models.ExchangeRateRequest) (models.ExchangeRate, error) {
+	tracer := otel.Tracer("exchange-rate-store")
+	ctx, span := tracer.Start(ctx, "CreateExchangeRate-Store")
+	defer span.End()
+
+	rateDate := rateReq.RateDate
+	if rateDate == "" {
+		rateDate = time.Now().Format(models.DateLayout)
+	}
+
+	row := s.db.QueryRowContext(ctx,
+		`INSERT INTO exchange_rate (id, base_currency, quote_currency, rate, rate_date, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (base_currency, quote_currency, rate_date) DO UPDATE SET rate = EXCLUDED.rate
+			RETURNING id, base_currency, quote_currency, rate, rate_date, created_at`,
+		uuid.New(), rateReq.BaseCurrency, rateReq.QuoteCurrency, rateReq.Rate, rateDate, time.Now())
+	return scanExchangeRate(row)
+}
+
+func (s ExchangeRateStore) DeleteExchangeRate(ctx context.Context, id string) (models.ExchangeRate, error) {
+	tracer := otel.Tracer("exchange-rate-store")
+	ctx, span := tracer.Start(ctx, "DeleteExchangeRate-Store")
+	defer span.End()
+
+	row := s.db.QueryRowContext(ctx,
+		`DELETE FROM exchange_rate WHERE id = $1
+			RETURNING id, base_currency, quote_currency, rate, rate_date, created_at`, id)
+	rate, err := scanExchangeRate(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return rate, errors.New("exchange rate does not exist")
+		}
+		return rate, err
+	}
+	return rate, nil
+}
@@ -0,0 +1,120 @@
+package favorite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/gloonch/CarZone/models"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"go.opentelemetry.io/otel"
+)
+
+type FavoriteStore struct {
+	db *sql.DB
+}
+
+func NewFavoriteStore(db *sql.DB) *FavoriteStore {
+	return &FavoriteStore{
+		db: db,
+	}
+}
+
+// GetFavorites returns the favourites of a user, most recent first. Only the
+// ID of each car is filled in.
+func (s FavoriteStore) GetFavorites(ctx context.Context, username string) ([]models.Favorite, error) {
+	tracer := otel.Tracer("favorite-store")
+	ctx, span := tracer.Start(ctx, "GetFavorites-Store")
+	defer span.End()
+
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT car_id, created_at FROM favorite WHERE username = $1 ORDER BY created_at DESC", username)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var favorites []models.Favorite
+	for rows.Next() {
+		var favorite models.Favorite
+		if err := rows.Scan(&favorite.Car.ID, &favorite.CreatedAt); err != nil {
+			return nil, err
+		}
+		favorites = append(favorites, favorite)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return favorites, nil
+}
+
+// FavoriteCarIDs reports which of carIDs the user has starred.
+func (s FavoriteStore) FavoriteCarIDs(ctx context.Context, username string, carIDs []string) (map[uuid.UUID]bool, error) {
+	tracer := otel.Tracer("favorite-store")
+	ctx, span := tracer.Start(ctx, "FavoriteCarIDs-Store")
+	defer span.End()
+
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT car_id FROM favorite WHERE username = $1 AND car_id = ANY($2::uuid[])", username, pq.Array(carIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	favorites := make(map[uuid.UUID]bool)
+	for rows.Next() {
+		var carID uuid.UUID
+		if err := rows.Scan(&carID); err != nil {
+			return nil, err
+		}
+		favorites[carID] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return favorites, nil
+}
+
+// AddFavorite stars a car for the user. Starring a car twice keeps the
+// original timestamp.
+func (s FavoriteStore) AddFavorite(ctx context.Context, username string, carID uuid.UUID) (time.Time, error) {
+	tracer := otel.Tracer("favorite-store")
+	ctx, span := tracer.Start(ctx, "AddFavorite-Store")
+	defer span.End()
+
+	var createdAt time.Time
+	err := s.db.QueryRowContext(ctx,
+		`INSERT INTO favorite (username, car_id, created_at) VALUES ($1, $2, $3)
+			ON CONFLICT (username, car_id) DO UPDATE SET username = EXCLUDED.username
+			RETURNING created_at`,
+		username, carID, time.Now()).Scan(&createdAt)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23503" {
+			return createdAt, models.ErrCarNotFound
+		}
+		return createdAt, err
+	}
+	return createdAt, nil
+}
+
+func (s FavoriteStore) RemoveFavorite(ctx context.Context, username string, carID string) error {
+	tracer := otel.Tracer("favorite-store")
+	ctx, span := tracer.Start(ctx, "RemoveFavorite-Store")
+	defer span.End()
+
+	result, err := s.db.ExecContext(ctx, "DELETE FROM favorite WHERE username = $1 AND car_id = $2", username, carID)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return models.ErrFavoriteNotFound
+	}
+	return nil
+}
@@ -2,15 +2,21 @@ package store
 
 import (
 	"context"
+	"time"
 
 	"github.com/gloonch/CarZone/models"
+	"github.com/google/uuid"
 )
 
 type CarStoreInterface interface {
 	GetCarByID(ctx context.Context, id string) (models.Car, error)
-	GetCarByBrand(ctx context.Context, brand string, isEngine bool) ([]models.Car, error)
-	CreateCar(ctx context.Context, carReq *models.CarRequest) (models.Car, error)
-	UpdateCar(ctx context.Context, id string, carReq *models.CarRequest) (models.Car, error)
+	GetCarByBrand(ctx context.Context, query models.CarQuery) ([]models.Car, error)
+	GetCarsByIDs(ctx context.Context, ids []string) ([]models.Car, error)
+	GetAllCars(ctx context.Context) ([]models.Car, error)
+	CreateCar(ctx context.Context, carReq *models.CarRequest, username string) (models.Car, error)
+	UpdateCar(ctx context.Context, id string, carReq *models.CarRequest, username string) (models.Car, error)
+	GetOdometerAudit(ctx context.Context, carID string) ([]models.OdometerAudit, error)
+	GetTags(ctx context.Context) ([]models.Tag, error)
 	DeleteCar(ctx context.Context, id string) (models.Car, error)
 }
 
@@ -20,3 +26,103 @@ type EngineStoreInterface interface {
 	UpdateEngine(ctx context.Context, id string, engineReq *models.EngineRequest) (models.Engine, error)
 	DeleteEngine(ctx context.Context, id string) (models.Engine, error)
 }
+
+type ExchangeRateStoreInterface interface {
+	GetExchangeRates(ctx context.Context) ([]models.ExchangeRate, error)
+	LatestExchangeRate(ctx context.Context, base, quote string) (models.ExchangeRate, error)
+	CreateExchangeRate(ctx context.Context, rateReq *models.ExchangeRateRequest) (models.ExchangeRate, error)
+	DeleteExchangeRate(ctx context.Context, id string) (models.ExchangeRate, error)
+}
+
+type MediaStoreInterface interface {
+	GetMediaByCar(ctx context.Context, carID string) ([]models.CarMedia, error)
+	MediaByID(ctx context.Context, carID string, mediaID string) (models.CarMedia, error)
+	CreateMedia(ctx context.Context, media *models.CarMedia) (models.CarMedia, error)
+	DeleteMedia(ctx context.Context, carID string, mediaID string) (models.CarMedia, error)
+}
+
+type FavoriteStoreInterface interface {
+	GetFavorites(ctx context.Context, username string) ([]models.Favorite, error)
+	FavoriteCarIDs(ctx context.Context, username string, carIDs []string) (map[uuid.UUID]bool, error)
+	AddFavorite(ctx context.Context, username string, carID uuid.UUID) (time.Time, error)
+	RemoveFavorite(ctx context.Context, username string, carID string) error
+}
+
+type SavedSearchStoreInterface interface {
+	GetSavedSearches(ctx context.Context, username string) ([]models.SavedSearch, error)
+	GetTenantSavedSearches(ctx context.Context, tenantID string) ([]models.SavedSearch, error)
+	CreateSavedSearch(ctx context.Context, username string, searchReq *models.SavedSearchRequest) (models.SavedSearch, error)
+	DeleteSavedSearch(ctx context.Context, username string, id string) (models.SavedSearch, error)
+	CreateNotifications(ctx context.Context, notifications []models.Notification) error
+	GetNotifications(ctx context.Context, username string, unreadOnly bool) ([]models.Notification, error)
+	MarkNotificationRead(ctx context.Context, username string, id string) error
+}
+
+type ReviewStoreInterface interface {
+	GetReviewsForCar(ctx context.Context, carID string) ([]models.Review, error)
+	GetReviewsByStatus(ctx context.Context, status string) ([]models.Review, error)
+	CreateReview(ctx context.Context, username string, reviewReq *models.ReviewRequest) (models.Review, error)
+	UpdateReviewStatus(ctx context.Context, id string, status string) (models.Review, error)
+	DeleteReview(ctx context.Context, username string, id string) (models.Review, error)
+}
+
+type ServiceRecordStoreInterface interface {
+	GetServiceRecords(ctx context.Context, carID string) ([]models.ServiceRecord, error)
+	LatestServiceRecords(ctx context.Context) ([]models.ServiceRecord, error)
+	CreateServiceRecord(ctx context.Context, carID string, recordReq *models.ServiceRecordRequest) (models.ServiceRecord, error)
+	DeleteServiceRecord(ctx context.Context, carID string, id string) (models.ServiceRecord, error)
+}
+
+type DepreciationStoreInterface interface {
+	GetCurves(ctx context.Context) ([]models.DepreciationCurve, error)
+	CurveForBrand(ctx context.Context, brand string) (models.DepreciationCurve, error)
+	SaveCurve(ctx context.Context, brand string, curveReq *models.DepreciationCurveRequest) (models.DepreciationCurve, error)
+	DeleteCurve(ctx context.Context, brand string) (models.DepreciationCurve, error)
+}
+
+type QuoteStoreInterface interface {
+	GetQuote(ctx context.Context, id string, tenantID string) (models.Quote, error)
+	GetQuotesByUser(ctx context.Context, username string) ([]models.Quote, error)
+	CreateQuote(ctx context.Context, quote models.Quote) (models.Quote, error)
+}
+
+type OptionStoreInterface interface {
+	GetPackagesForCar(ctx context.Context, carID string) ([]models.OptionPackage, error)
+	CreatePackage(ctx context.Context, packageReq *models.OptionPackageRequest) (models.OptionPackage, error)
+	PackageByID(ctx context.Context, id string) (models.OptionPackage, error)
+	DeletePackage(ctx context.Context, id string) (models.OptionPackage, error)
+}
+
+type UserStoreInterface interface {
+	GetUserByUsername(ctx context.Context, username string) (models.User, error)
+	GetUsers(ctx context.Context, tenantID string) ([]models.User, error)
+	CountUsers(ctx context.Context) (int64, error)
+	CreateUser(ctx context.Context, username string, passwordHash string, role string, tenantID string, location string) (models.User, error)
+	UpsertOIDCUser(ctx context.Context, subject string, username string, role string, tenantID string) (models.User, error)
+	UpdatePassword(ctx context.Context, username string, passwordHash string) error
+}
+
+type AuditStoreInterface interface {
+	RecordDenial(ctx context.Context, denial models.AccessDenial) error
+	GetDenials(ctx context.Context, username string, limit int) ([]models.AccessDenial, error)
+}
+
+type TokenStoreInterface interface {
+	CreateRefreshToken(ctx context.Context, token models.RefreshToken) error
+	UseRefreshToken(ctx context.Context, tokenHash string, now time.Time) (models.RefreshToken, error)
+	RevokeSession(ctx context.Context, sessionID string, now time.Time) error
+	RevokeUserSessions(ctx context.Context, username string, now time.Time) error
+	IsRevoked(ctx context.Context, jti string) (bool, error)
+}
+
+type APIKeyStoreInterface interface {
+	GetAPIKeys(ctx context.Context, tenantID string) ([]models.APIKey, error)
+	CreateAPIKey(ctx context.Context, key models.APIKey) (models.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id string, tenantID string, now time.Time) (models.APIKey, error)
+	UseAPIKey(ctx context.Context, keyHash string, now time.Time) (models.APIKey, error)
+}
+
+type PasswordResetStoreInterface interface {
+	CreateResetToken(ctx context.Context, token models.PasswordResetToken) error
+	ResetPassword(ctx context.Context, tokenHash string, passwordHash string, now time.Time) (string, error)
+}
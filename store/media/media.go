@@ -0,0 +1,168 @@
+package media
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/gloonch/CarZone/models"
+	"github.com/gloonch/CarZone/store"
+	"go.opentelemetry.io/otel"
+)
+
+type MediaStore struct {
+	db *sql.DB
+}
+
+func NewMediaStore(db *sql.DB) *MediaStore {
+	return &MediaStore{
+		db: db,
+	}
+}
+
+// tenantCar restricts a query on car_id ($1) to the cars of the tenant ($2).
+const tenantCar = ` AND car_id IN (SELECT id FROM car WHERE tenant_id = $2)`
+
+const mediaColumns = `id, car_id, kind, file_name, content_type, size_bytes, blob_key, COALESCE(thumbnail_key, ''), created_at`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanMedia(row rowScanner) (models.CarMedia, error) {
+	var media models.CarMedia
+	err := row.Scan(
+		&media.ID,
+		&media.CarID,
+		&media.Kind,
+		&media.FileName,
+		&media.ContentType,
+		&media.Size,
+		&media.BlobKey,
+		&media.ThumbnailKey,
+		&media.CreatedAt,
+	)
+	if err != nil {
+		return media, err
+	}
+	media.SetURLs()
+	return media, nil
+}
+
+func (s MediaStore) GetMediaByCar(ctx context.Context, carID string) ([]models.CarMedia, error) {
+	tracer := otel.Tracer("media-store")
+	ctx, span := tracer.Start(ctx, "GetMediaByCar-Store")
+	defer span.End()
+
+	tx, tenant, err := store.BeginTenantTx(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx,
+		"SELECT "+mediaColumns+" FROM car_media WHERE car_id = $1"+tenantCar+" ORDER BY created_at", carID, tenant)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var media []models.CarMedia
+	for rows.Next() {
+		m, err := scanMedia(rows)
+		if err != nil {
+			return nil, err
+		}
+		media = append(media, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return media, nil
+}
+
+func (s MediaStore) MediaByID(ctx context.Context, carID string, mediaID string) (models.CarMedia, error) {
+	tracer := otel.Tracer("media-store")
+	ctx, span := tracer.Start(ctx, "MediaByID-Store")
+	defer span.End()
+
+	tx, tenant, err := store.BeginTenantTx(ctx, s.db)
+	if err != nil {
+		return models.CarMedia{}, err
+	}
+	defer tx.Rollback()
+
+	row := tx.QueryRowContext(ctx,
+		"SELECT "+mediaColumns+" FROM car_media WHERE car_id = $1"+tenantCar+" AND id = $3", carID, tenant, mediaID)
+	media, err := scanMedia(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return media, models.ErrMediaNotFound
+		}
+		return media, err
+	}
+	return media, nil
+}
+
+func (s MediaStore) CreateMedia(ctx context.Context, media *models.CarMedia) (created models.CarMedia, err error) {
+	tracer := otel.Tracer("media-store")
+	ctx, span := tracer.Start(ctx, "CreateMedia-Store")
+	defer span.End()
+
+	var thumbnailKey interface{}
+	if media.ThumbnailKey != "" {
+		thumbnailKey = media.ThumbnailKey
+	}
+
+	tx, tenant, err := store.BeginTenantTx(ctx, s.db)
+	if err != nil {
+		return created, err
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	row := tx.QueryRowContext(ctx,
+		`INSERT INTO car_media (id, car_id, kind, file_name, content_type, size_bytes, blob_key, thumbnail_key, created_at)
+			SELECT $1, $2, $3, $4, $5, $6, $7, $8, $9 WHERE EXISTS (SELECT 1 FROM car WHERE id = $2 AND tenant_id = $10)
+			RETURNING `+mediaColumns,
+		media.ID, media.CarID, media.Kind, media.FileName, media.ContentType, media.Size,
+		media.BlobKey, thumbnailKey, media.CreatedAt, tenant)
+	created, err = scanMedia(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return created, models.ErrCarNotFound
+	}
+	return created, err
+}
+
+func (s MediaStore) DeleteMedia(ctx context.Context, carID string, mediaID string) (deleted models.CarMedia, err error) {
+	tracer := otel.Tracer("media-store")
+	ctx, span := tracer.Start(ctx, "DeleteMedia-Store")
+	defer span.End()
+
+	tx, tenant, err := store.BeginTenantTx(ctx, s.db)
+	if err != nil {
+		return deleted, err
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	row := tx.QueryRowContext(ctx,
+		"DELETE FROM car_media WHERE car_id = $1"+tenantCar+" AND id = $3 RETURNING "+mediaColumns, carID, tenant, mediaID)
+	deleted, err = scanMedia(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return deleted, models.ErrMediaNotFound
+	}
+	return deleted, err
+}
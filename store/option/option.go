@@ -0,0 +1,194 @@
+package option
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/gloonch/CarZone/models"
+	"github.com/gloonch/CarZone/store"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"go.opentelemetry.io/otel"
+)
+
+type OptionStore struct {
+	db *sql.DB
+}
+
+func NewOptionStore(db *sql.DB) *OptionStore {
+	return &OptionStore{
+		db: db,
+	}
+}
+
+const packageColumns = `id, car_id, COALESCE(brand, ''), COALESCE(model, ''), code, name, description,
+	price_delta, currency, requires, excludes, created_at`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanPackage(row rowScanner) (models.OptionPackage, error) {
+	var pkg models.OptionPackage
+	err := row.Scan(
+		&pkg.ID,
+		&pkg.CarID,
+		&pkg.Brand,
+		&pkg.Model,
+		&pkg.Code,
+		&pkg.Name,
+		&pkg.Description,
+		&pkg.PriceDelta.Amount,
+		&pkg.PriceDelta.Currency,
+		pq.Array(&pkg.Requires),
+		pq.Array(&pkg.Excludes),
+		&pkg.CreatedAt,
+	)
+	if pkg.Requires == nil {
+		pkg.Requires = []string{}
+	}
+	if pkg.Excludes == nil {
+		pkg.Excludes = []string{}
+	}
+	return pkg, err
+}
+
+// GetPackagesForCar returns the packages offered on a car: its own packages
+// and those of its trim, with the car's own package winning on a shared
+// code.
+func (s OptionStore) GetPackagesForCar(ctx context.Context, carID string) ([]models.OptionPackage, error) {
+	tracer := otel.Tracer("option-store")
+	ctx, span := tracer.Start(ctx, "GetPackagesForCar-Store")
+	defer span.End()
+
+	tx, tenant, err := store.BeginTenantTx(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx,
+		`SELECT DISTINCT ON (p.code) `+packageColumns+` FROM option_package p
+			WHERE p.tenant_id = $2 AND (p.car_id = $1
+				OR (p.car_id IS NULL AND EXISTS (SELECT 1 FROM car c
+					WHERE c.id = $1 AND c.tenant_id = $2 AND c.brand = p.brand AND c.name = p.model)))
+			ORDER BY p.code, p.car_id IS NULL`, carID, tenant)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	packages := []models.OptionPackage{}
+	for rows.Next() {
+		pkg, err := scanPackage(rows)
+		if err != nil {
+			return nil, err
+		}
+		packages = append(packages, pkg)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return packages, nil
+}
+
+// CreatePackage stores a package for the tenant of the request. A package of
+// a single car is refused unless the car belongs to that tenant.
+func (s OptionStore) CreatePackage(ctx context.Context, packageReq *models.OptionPackageRequest) (pkg models.OptionPackage, err error) {
+	tracer := otel.Tracer("option-store")
+	ctx, span := tracer.Start(ctx, "CreatePackage-Store")
+	defer span.End()
+
+	var brand, model interface{}
+	if packageReq.CarID == nil {
+		brand, model = packageReq.Brand, packageReq.Model
+	}
+
+	tx, tenant, err := store.BeginTenantTx(ctx, s.db)
+	if err != nil {
+		return pkg, err
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	row := tx.QueryRowContext(ctx,
+		`INSERT INTO option_package (id, car_id, brand, model, code, name, description, price_delta, currency,
+				requires, excludes, created_at, tenant_id)
+			SELECT $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
+			WHERE $2::uuid IS NULL OR EXISTS (SELECT 1 FROM car WHERE id = $2 AND tenant_id = $13)
+			RETURNING `+packageColumns,
+		uuid.New(), packageReq.CarID, brand, model, packageReq.Code, packageReq.Name, packageReq.Description,
+		packageReq.PriceDelta.Amount, packageReq.PriceDelta.Currency, pq.Array(packageReq.Requires),
+		pq.Array(packageReq.Excludes), time.Now(), tenant)
+	pkg, err = scanPackage(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return pkg, models.ErrCarNotFound
+		}
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) {
+			switch pqErr.Code {
+			case "23505":
+				return pkg, models.ErrPackageCodeTaken
+			case "23503":
+				return pkg, models.ErrCarNotFound
+			}
+		}
+		return pkg, err
+	}
+	return pkg, nil
+}
+
+func (s OptionStore) PackageByID(ctx context.Context, id string) (models.OptionPackage, error) {
+	tracer := otel.Tracer("option-store")
+	ctx, span := tracer.Start(ctx, "PackageByID-Store")
+	defer span.End()
+
+	tx, tenant, err := store.BeginTenantTx(ctx, s.db)
+	if err != nil {
+		return models.OptionPackage{}, err
+	}
+	defer tx.Rollback()
+
+	row := tx.QueryRowContext(ctx, `SELECT `+packageColumns+` FROM option_package WHERE id = $1 AND tenant_id = $2`, id, tenant)
+	pkg, err := scanPackage(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return pkg, models.ErrPackageNotFound
+	}
+	return pkg, err
+}
+
+func (s OptionStore) DeletePackage(ctx context.Context, id string) (pkg models.OptionPackage, err error) {
+	tracer := otel.Tracer("option-store")
+	ctx, span := tracer.Start(ctx, "DeletePackage-Store")
+	defer span.End()
+
+	tx, tenant, err := store.BeginTenantTx(ctx, s.db)
+	if err != nil {
+		return pkg, err
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	row := tx.QueryRowContext(ctx,
+		`DELETE FROM option_package WHERE id = $1 AND tenant_id = $2 RETURNING `+packageColumns, id, tenant)
+	pkg, err = scanPackage(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return pkg, models.ErrPackageNotFound
+	}
+	return pkg, err
+}
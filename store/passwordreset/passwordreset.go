@@ -0,0 +1,93 @@
+package passwordreset
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/gloonch/CarZone/models"
+	"go.opentelemetry.io/otel"
+)
+
+type PasswordResetStore struct {
+	db *sql.DB
+}
+
+func NewPasswordResetStore(db *sql.DB) *PasswordResetStore {
+	return &PasswordResetStore{
+		db: db,
+	}
+}
+
+// CreateResetToken stores a reset token and invalidates the user's earlier
+// unused tokens, so only the most recently sent one works.
+func (s PasswordResetStore) CreateResetToken(ctx context.Context, token models.PasswordResetToken) (err error) {
+	tracer := otel.Tracer("passwordreset-store")
+	ctx, span := tracer.Start(ctx, "CreateResetToken-Store")
+	defer span.End()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	_, err = tx.ExecContext(ctx,
+		`DELETE FROM password_reset_token WHERE (username = $1 AND used_at IS NULL) OR expires_at < $2`,
+		token.Username, token.CreatedAt)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO password_reset_token (id, username, token_hash, expires_at, created_at, used_at)
+			VALUES ($1, $2, $3, $4, $5, $6)`,
+		token.ID, token.Username, token.TokenHash, token.ExpiresAt, token.CreatedAt, token.UsedAt)
+	return err
+}
+
+// ResetPassword uses the reset token with the given hash and sets the
+// password of its user in one transaction, returning the username. Unknown,
+// expired and already used tokens give models.ErrInvalidResetToken.
+func (s PasswordResetStore) ResetPassword(ctx context.Context, tokenHash string, passwordHash string, now time.Time) (username string, err error) {
+	tracer := otel.Tracer("passwordreset-store")
+	ctx, span := tracer.Start(ctx, "ResetPassword-Store")
+	defer span.End()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	err = tx.QueryRowContext(ctx,
+		`UPDATE password_reset_token SET used_at = $2
+			WHERE token_hash = $1 AND used_at IS NULL AND expires_at > $2
+			RETURNING username`,
+		tokenHash, now).Scan(&username)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = models.ErrInvalidResetToken
+		}
+		return "", err
+	}
+	_, err = tx.ExecContext(ctx,
+		`UPDATE users SET password_hash = $2, updated_at = $3 WHERE username = $1`,
+		username, passwordHash, now)
+	if err != nil {
+		return "", err
+	}
+	return username, nil
+}
@@ -0,0 +1,129 @@
+package quote
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/gloonch/CarZone/models"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"go.opentelemetry.io/otel"
+)
+
+type QuoteStore struct {
+	db *sql.DB
+}
+
+func NewQuoteStore(db *sql.DB) *QuoteStore {
+	return &QuoteStore{
+		db: db,
+	}
+}
+
+const quoteColumns = `id, car_id, username, type, price, currency, packages, down_payment, financed, apr, term_months,
+	residual, monthly_payment, total_interest, total_cost, schedule, created_at, expires_at`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanQuote(row rowScanner) (models.Quote, error) {
+	var quote models.Quote
+	var schedule []byte
+	err := row.Scan(
+		&quote.ID,
+		&quote.CarID,
+		&quote.Username,
+		&quote.Type,
+		&quote.Price.Amount,
+		&quote.Price.Currency,
+		pq.Array(&quote.Packages),
+		&quote.DownPayment,
+		&quote.Financed,
+		&quote.APR,
+		&quote.TermMonths,
+		&quote.Residual,
+		&quote.MonthlyPayment,
+		&quote.TotalInterest,
+		&quote.TotalCost,
+		&schedule,
+		&quote.CreatedAt,
+		&quote.ExpiresAt,
+	)
+	if err != nil {
+		return quote, err
+	}
+	if err := json.Unmarshal(schedule, &quote.Schedule); err != nil {
+		return quote, err
+	}
+	return quote, nil
+}
+
+// GetQuote returns a quote issued to a user of the tenant.
+func (s QuoteStore) GetQuote(ctx context.Context, id string, tenantID string) (models.Quote, error) {
+	tracer := otel.Tracer("quote-store")
+	ctx, span := tracer.Start(ctx, "GetQuote-Store")
+	defer span.End()
+
+	row := s.db.QueryRowContext(ctx,
+		`SELECT `+quoteColumns+` FROM quote
+			WHERE id = $1 AND username IN (SELECT username FROM users WHERE tenant_id = $2)`, id, tenantID)
+	quote, err := scanQuote(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return quote, models.ErrQuoteNotFound
+		}
+		return quote, err
+	}
+	return quote, nil
+}
+
+// GetQuotesByUser lists the quotes issued by username, newest first. The
+// schedules are included.
+func (s QuoteStore) GetQuotesByUser(ctx context.Context, username string) ([]models.Quote, error) {
+	tracer := otel.Tracer("quote-store")
+	ctx, span := tracer.Start(ctx, "GetQuotesByUser-Store")
+	defer span.End()
+
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT `+quoteColumns+` FROM quote WHERE username = $1 ORDER BY created_at DESC`, username)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	quotes := []models.Quote{}
+	for rows.Next() {
+		quote, err := scanQuote(rows)
+		if err != nil {
+			return nil, err
+		}
+		quotes = append(quotes, quote)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return quotes, nil
+}
+
+func (s QuoteStore) CreateQuote(ctx context.Context, quote models.Quote) (models.Quote, error) {
+	tracer := otel.Tracer("quote-store")
+	ctx, span := tracer.Start(ctx, "CreateQuote-Store")
+	defer span.End()
+
+	schedule, err := json.Marshal(quote.Schedule)
+	if err != nil {
+		return quote, err
+	}
+
+	row := s.db.QueryRowContext(ctx,
+		`INSERT INTO quote (`+quoteColumns+`)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
+			RETURNING `+quoteColumns,
+		uuid.New(), quote.CarID, quote.Username, quote.Type, quote.Price.Amount, quote.Price.Currency,
+		pq.Array(quote.Packages), quote.DownPayment, quote.Financed, quote.APR, quote.TermMonths, quote.Residual, quote.MonthlyPayment,
+		quote.TotalInterest, quote.TotalCost, schedule, quote.CreatedAt, quote.ExpiresAt)
+	return scanQuote(row)
+}
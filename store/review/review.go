@@ -0,0 +1,203 @@
+package review
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/gloonch/CarZone/models"
+	"github.com/gloonch/CarZone/store"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"go.opentelemetry.io/otel"
+)
+
+type ReviewStore struct {
+	db *sql.DB
+}
+
+func NewReviewStore(db *sql.DB) *ReviewStore {
+	return &ReviewStore{
+		db: db,
+	}
+}
+
+const reviewColumns = `id, username, car_id, COALESCE(brand, ''), COALESCE(model, ''), rating, text, status, created_at, updated_at`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanReview(row rowScanner) (models.Review, error) {
+	var review models.Review
+	err := row.Scan(
+		&review.ID,
+		&review.Username,
+		&review.CarID,
+		&review.Brand,
+		&review.Model,
+		&review.Rating,
+		&review.Text,
+		&review.Status,
+		&review.CreatedAt,
+		&review.UpdatedAt,
+	)
+	return review, err
+}
+
+// queryReviews runs query in a transaction of the request's tenant; the
+// tenant is passed as the last argument.
+func (s ReviewStore) queryReviews(ctx context.Context, query string, args ...interface{}) ([]models.Review, error) {
+	tx, tenant, err := store.BeginTenantTx(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, query, append(args, tenant)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reviews []models.Review
+	for rows.Next() {
+		review, err := scanReview(rows)
+		if err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, review)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return reviews, nil
+}
+
+// GetReviewsForCar returns the approved reviews of a car together with the
+// approved reviews of its model.
+func (s ReviewStore) GetReviewsForCar(ctx context.Context, carID string) ([]models.Review, error) {
+	tracer := otel.Tracer("review-store")
+	ctx, span := tracer.Start(ctx, "GetReviewsForCar-Store")
+	defer span.End()
+
+	return s.queryReviews(ctx,
+		`SELECT `+reviewColumns+` FROM review r
+			WHERE r.tenant_id = $2 AND r.status = 'approved' AND (r.car_id = $1
+				OR (r.car_id IS NULL AND EXISTS (SELECT 1 FROM car c
+					WHERE c.id = $1 AND c.tenant_id = $2 AND c.brand = r.brand AND c.name = r.model)))
+			ORDER BY r.created_at DESC`, carID)
+}
+
+func (s ReviewStore) GetReviewsByStatus(ctx context.Context, status string) ([]models.Review, error) {
+	tracer := otel.Tracer("review-store")
+	ctx, span := tracer.Start(ctx, "GetReviewsByStatus-Store")
+	defer span.End()
+
+	return s.queryReviews(ctx,
+		`SELECT `+reviewColumns+` FROM review WHERE status = $1 AND tenant_id = $2 ORDER BY created_at`, status)
+}
+
+// CreateReview stores a pending review for the tenant of the request. A
+// review of a single car is refused unless the car belongs to that tenant.
+func (s ReviewStore) CreateReview(ctx context.Context, username string, reviewReq *models.ReviewRequest) (review models.Review, err error) {
+	tracer := otel.Tracer("review-store")
+	ctx, span := tracer.Start(ctx, "CreateReview-Store")
+	defer span.End()
+
+	tx, tenant, err := store.BeginTenantTx(ctx, s.db)
+	if err != nil {
+		return review, err
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	now := time.Now()
+	row := tx.QueryRowContext(ctx,
+		`INSERT INTO review (id, username, car_id, brand, model, rating, text, status, created_at, updated_at, tenant_id)
+			SELECT $1, $2, $3, NULLIF($4, ''), NULLIF($5, ''), $6, $7, $8, $9, $9, $10
+			WHERE $3::uuid IS NULL OR EXISTS (SELECT 1 FROM car WHERE id = $3 AND tenant_id = $10)
+			RETURNING `+reviewColumns,
+		uuid.New(), username, reviewReq.CarID, reviewReq.Brand, reviewReq.Model, reviewReq.Rating, reviewReq.Text,
+		models.ReviewStatusPending, now, tenant)
+	review, err = scanReview(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return review, errors.New("car does not exist")
+		}
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) {
+			switch pqErr.Code {
+			case "23505":
+				return review, errors.New("you have already reviewed this car or model")
+			case "23503":
+				return review, errors.New("car does not exist")
+			}
+		}
+		return review, err
+	}
+	return review, nil
+}
+
+func (s ReviewStore) UpdateReviewStatus(ctx context.Context, id string, status string) (review models.Review, err error) {
+	tracer := otel.Tracer("review-store")
+	ctx, span := tracer.Start(ctx, "UpdateReviewStatus-Store")
+	defer span.End()
+
+	tx, tenant, err := store.BeginTenantTx(ctx, s.db)
+	if err != nil {
+		return review, err
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	row := tx.QueryRowContext(ctx,
+		`UPDATE review SET status = $2, updated_at = $3 WHERE id = $1 AND tenant_id = $4 RETURNING `+reviewColumns,
+		id, status, time.Now(), tenant)
+	review, err = scanReview(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return review, errors.New("review does not exist")
+	}
+	return review, err
+}
+
+func (s ReviewStore) DeleteReview(ctx context.Context, username string, id string) (review models.Review, err error) {
+	tracer := otel.Tracer("review-store")
+	ctx, span := tracer.Start(ctx, "DeleteReview-Store")
+	defer span.End()
+
+	tx, tenant, err := store.BeginTenantTx(ctx, s.db)
+	if err != nil {
+		return review, err
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	row := tx.QueryRowContext(ctx,
+		`DELETE FROM review WHERE id = $1 AND username = $2 AND tenant_id = $3 RETURNING `+reviewColumns,
+		id, username, tenant)
+	review, err = scanReview(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return review, errors.New("review does not exist")
+	}
+	return review, err
+}
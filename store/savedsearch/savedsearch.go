@@ -0,0 +1,193 @@
+package savedsearch
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/gloonch/CarZone/models"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+)
+
+type SavedSearchStore struct {
+	db *sql.DB
+}
+
+func NewSavedSearchStore(db *sql.DB) *SavedSearchStore {
+	return &SavedSearchStore{
+		db: db,
+	}
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanSavedSearch(row rowScanner) (models.SavedSearch, error) {
+	var search models.SavedSearch
+	var filter []byte
+	if err := row.Scan(&search.ID, &search.Username, &search.Name, &filter, &search.CreatedAt); err != nil {
+		return search, err
+	}
+	if err := json.Unmarshal(filter, &search.Filter); err != nil {
+		return search, err
+	}
+	return search, nil
+}
+
+func (s SavedSearchStore) querySavedSearches(ctx context.Context, query string, args ...interface{}) ([]models.SavedSearch, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var searches []models.SavedSearch
+	for rows.Next() {
+		search, err := scanSavedSearch(rows)
+		if err != nil {
+			return nil, err
+		}
+		searches = append(searches, search)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return searches, nil
+}
+
+func (s SavedSearchStore) GetSavedSearches(ctx context.Context, username string) ([]models.SavedSearch, error) {
+	tracer := otel.Tracer("saved-search-store")
+	ctx, span := tracer.Start(ctx, "GetSavedSearches-Store")
+	defer span.End()
+
+	return s.querySavedSearches(ctx,
+		"SELECT id, username, name, filter, created_at FROM saved_search WHERE username = $1 ORDER BY created_at", username)
+}
+
+// GetTenantSavedSearches returns the searches of every user of a tenant, for
+// matching.
+func (s SavedSearchStore) GetTenantSavedSearches(ctx context.Context, tenantID string) ([]models.SavedSearch, error) {
+	tracer := otel.Tracer("saved-search-store")
+	ctx, span := tracer.Start(ctx, "GetTenantSavedSearches-Store")
+	defer span.End()
+
+	return s.querySavedSearches(ctx,
+		`SELECT id, username, name, filter, created_at FROM saved_search
+			WHERE username IN (SELECT username FROM users WHERE tenant_id = $1)`, tenantID)
+}
+
+func (s SavedSearchStore) CreateSavedSearch(ctx context.Context, username string, searchReq *models.SavedSearchRequest) (models.SavedSearch, error) {
+	tracer := otel.Tracer("saved-search-store")
+	ctx, span := tracer.Start(ctx, "CreateSavedSearch-Store")
+	defer span.End()
+
+	filter, err := json.Marshal(searchReq.Filter)
+	if err != nil {
+		return models.SavedSearch{}, err
+	}
+
+	row := s.db.QueryRowContext(ctx,
+		`INSERT INTO saved_search (id, username, name, filter, created_at) VALUES ($1, $2, $3, $4, $5)
+			RETURNING id, username, name, filter, created_at`,
+		uuid.New(), username, searchReq.Name, filter, time.Now())
+	return scanSavedSearch(row)
+}
+
+func (s SavedSearchStore) DeleteSavedSearch(ctx context.Context, username string, id string) (models.SavedSearch, error) {
+	tracer := otel.Tracer("saved-search-store")
+	ctx, span := tracer.Start(ctx, "DeleteSavedSearch-Store")
+	defer span.End()
+
+	row := s.db.QueryRowContext(ctx,
+		`DELETE FROM saved_search WHERE username = $1 AND id = $2
+			RETURNING id, username, name, filter, created_at`, username, id)
+	search, err := scanSavedSearch(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return search, errors.New("saved search does not exist")
+		}
+		return search, err
+	}
+	return search, nil
+}
+
+func (s SavedSearchStore) CreateNotifications(ctx context.Context, notifications []models.Notification) (err error) {
+	tracer := otel.Tracer("saved-search-store")
+	ctx, span := tracer.Start(ctx, "CreateNotifications-Store")
+	defer span.End()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	for _, n := range notifications {
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO notification (id, username, saved_search_id, search_name, car_id, event, read, created_at)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+			n.ID, n.Username, n.SavedSearchID, n.SearchName, n.CarID, n.Event, n.Read, n.CreatedAt)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s SavedSearchStore) GetNotifications(ctx context.Context, username string, unreadOnly bool) ([]models.Notification, error) {
+	tracer := otel.Tracer("saved-search-store")
+	ctx, span := tracer.Start(ctx, "GetNotifications-Store")
+	defer span.End()
+
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT id, username, saved_search_id, search_name, car_id, event, read, created_at FROM notification
+			WHERE username = $1 AND (NOT $2 OR NOT read) ORDER BY created_at DESC`, username, unreadOnly)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var notifications []models.Notification
+	for rows.Next() {
+		var n models.Notification
+		err := rows.Scan(&n.ID, &n.Username, &n.SavedSearchID, &n.SearchName, &n.CarID, &n.Event, &n.Read, &n.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return notifications, nil
+}
+
+func (s SavedSearchStore) MarkNotificationRead(ctx context.Context, username string, id string) error {
+	tracer := otel.Tracer("saved-search-store")
+	ctx, span := tracer.Start(ctx, "MarkNotificationRead-Store")
+	defer span.End()
+
+	result, err := s.db.ExecContext(ctx,
+		"UPDATE notification SET read = TRUE WHERE username = $1 AND id = $2", username, id)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return errors.New("notification does not exist")
+	}
+	return nil
+}
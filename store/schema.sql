@@ -3,21 +3,32 @@
 ALTER TABLE IF EXISTS car
 DROP CONSTRAINT IF EXISTS fk_engine_id;
 
--- Truncate car table to clear existing data
-TRUNCATE TABLE car;
-
--- Truncate engine table to clear existing data
-TRUNCATE TABLE engine;
 -- Create engine table
 CREATE TABLE IF NOT EXISTS engine (
                                       id UUID PRIMARY KEY,
                                       displacement INT NOT NULL,
                                       no_of_cylinders INT NOT NULL,
                                       car_range INT NOT NULL,
+                                      engine_type VARCHAR(20) NOT NULL DEFAULT 'ICE',
+                                      power_kw NUMERIC(7, 2),
+                                      torque_nm NUMERIC(7, 2),
+                                      aspiration VARCHAR(30),
+                                      transmission VARCHAR(30),
+                                      battery_capacity_kwh NUMERIC(6, 2),
+                                      charging_power_kw NUMERIC(6, 2),
                                       created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
                                       updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
+-- Extended engine specs for existing databases; all optional except the type
+ALTER TABLE engine ADD COLUMN IF NOT EXISTS engine_type VARCHAR(20) NOT NULL DEFAULT 'ICE';
+ALTER TABLE engine ADD COLUMN IF NOT EXISTS power_kw NUMERIC(7, 2);
+ALTER TABLE engine ADD COLUMN IF NOT EXISTS torque_nm NUMERIC(7, 2);
+ALTER TABLE engine ADD COLUMN IF NOT EXISTS aspiration VARCHAR(30);
+ALTER TABLE engine ADD COLUMN IF NOT EXISTS transmission VARCHAR(30);
+ALTER TABLE engine ADD COLUMN IF NOT EXISTS battery_capacity_kwh NUMERIC(6, 2);
+ALTER TABLE engine ADD COLUMN IF NOT EXISTS charging_power_kw NUMERIC(6, 2);
+
 CREATE TABLE IF NOT EXISTS car (
                                    id UUID PRIMARY KEY,
                                    name VARCHAR(255) NOT NULL,
@@ -25,11 +36,314 @@ CREATE TABLE IF NOT EXISTS car (
     brand VARCHAR(255) NOT NULL,
     fuel_type VARCHAR(50) NOT NULL,
     engine_id UUID NOT NULL,
-    price DECIMAL(10, 2) NOT NULL,
+    price NUMERIC(19, 2) NOT NULL,
+    currency CHAR(3) NOT NULL DEFAULT 'USD',
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
     );
 
+-- Widen price for existing databases and record its currency
+ALTER TABLE car ALTER COLUMN price TYPE NUMERIC(19, 2);
+ALTER TABLE car ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'USD';
+
+-- Used-car details; new stock has no mileage and no previous owners
+ALTER TABLE car ADD COLUMN IF NOT EXISTS mileage BIGINT NOT NULL DEFAULT 0 CHECK (mileage >= 0);
+ALTER TABLE car ADD COLUMN IF NOT EXISTS condition VARCHAR(20) NOT NULL DEFAULT 'New';
+ALTER TABLE car ADD COLUMN IF NOT EXISTS previous_owners INT NOT NULL DEFAULT 0 CHECK (previous_owners >= 0);
+ALTER TABLE car ADD COLUMN IF NOT EXISTS damage_notes TEXT NOT NULL DEFAULT '';
+
+-- Free-form dealer attributes and shared tags, both filtered through GIN indexes
+ALTER TABLE car ADD COLUMN IF NOT EXISTS attributes JSONB NOT NULL DEFAULT '{}';
+CREATE INDEX IF NOT EXISTS idx_car_attributes ON car USING GIN (attributes jsonb_path_ops);
+ALTER TABLE car ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
+CREATE INDEX IF NOT EXISTS idx_car_tags ON car USING GIN (tags);
+
+-- Every car and engine belongs to a dealership tenant; existing rows go to the default tenant
+ALTER TABLE engine ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE car ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+CREATE INDEX IF NOT EXISTS idx_engine_tenant ON engine (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_car_tenant_brand ON car (tenant_id, brand);
+
+-- Who listed and last edited a car, and the dealership location it is listed at
+ALTER TABLE car ADD COLUMN IF NOT EXISTS location VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE car ADD COLUMN IF NOT EXISTS created_by VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE car ADD COLUMN IF NOT EXISTS updated_by VARCHAR(64) NOT NULL DEFAULT '';
+
+-- Tags used to live in a tag/car_tag join table; move them onto the car
+DO $$
+BEGIN
+    IF to_regclass('car_tag') IS NOT NULL THEN
+        UPDATE car c SET tags = ARRAY(
+            SELECT t.name FROM car_tag ct JOIN tag t ON t.id = ct.tag_id
+            WHERE ct.car_id = c.id ORDER BY t.name)
+        WHERE EXISTS (SELECT 1 FROM car_tag ct WHERE ct.car_id = c.id);
+    END IF;
+END $$;
+DROP TABLE IF EXISTS car_tag;
+DROP TABLE IF EXISTS tag;
+
+-- Admin corrections that lowered a car's odometer reading
+CREATE TABLE IF NOT EXISTS odometer_audit (
+    id UUID PRIMARY KEY,
+    car_id UUID NOT NULL REFERENCES car(id) ON DELETE CASCADE,
+    previous_mileage BIGINT NOT NULL,
+    new_mileage BIGINT NOT NULL,
+    reason TEXT NOT NULL,
+    username VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_odometer_audit_car ON odometer_audit (car_id, created_at DESC);
+
+-- Exchange rates maintained by admins; one rate per currency pair per day
+CREATE TABLE IF NOT EXISTS exchange_rate (
+    id UUID PRIMARY KEY,
+    base_currency CHAR(3) NOT NULL,
+    quote_currency CHAR(3) NOT NULL,
+    rate NUMERIC(20, 10) NOT NULL,
+    rate_date DATE NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (base_currency, quote_currency, rate_date)
+);
+
+-- Photos and documents attached to a car; the bytes live in the blob store
+CREATE TABLE IF NOT EXISTS car_media (
+    id UUID PRIMARY KEY,
+    car_id UUID NOT NULL REFERENCES car(id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL,
+    file_name VARCHAR(255) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    blob_key VARCHAR(255) NOT NULL,
+    thumbnail_key VARCHAR(255),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_car_media_car_id ON car_media (car_id);
+
+-- Cars starred by a user, keyed by the JWT subject
+CREATE TABLE IF NOT EXISTS favorite (
+    username VARCHAR(255) NOT NULL,
+    car_id UUID NOT NULL REFERENCES car(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (username, car_id)
+);
+
+-- Named car filters saved by users, and the inbox of matches found for them
+CREATE TABLE IF NOT EXISTS saved_search (
+    id UUID PRIMARY KEY,
+    username VARCHAR(255) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    filter JSONB NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_saved_search_username ON saved_search (username);
+
+CREATE TABLE IF NOT EXISTS notification (
+    id UUID PRIMARY KEY,
+    username VARCHAR(255) NOT NULL,
+    saved_search_id UUID NOT NULL REFERENCES saved_search(id) ON DELETE CASCADE,
+    search_name VARCHAR(100) NOT NULL,
+    car_id UUID NOT NULL REFERENCES car(id) ON DELETE CASCADE,
+    event VARCHAR(20) NOT NULL,
+    read BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_username ON notification (username, created_at);
+
+-- Reviews of a single car (car_id) or of a model (brand + model = car name)
+CREATE TABLE IF NOT EXISTS review (
+    id UUID PRIMARY KEY,
+    username VARCHAR(255) NOT NULL,
+    car_id UUID REFERENCES car(id) ON DELETE CASCADE,
+    brand VARCHAR(255),
+    model VARCHAR(255),
+    rating SMALLINT NOT NULL CHECK (rating BETWEEN 1 AND 5),
+    text TEXT NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK ((car_id IS NOT NULL) <> (brand IS NOT NULL AND model IS NOT NULL))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_review_user_car ON review (username, car_id) WHERE car_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_review_user_model ON review (username, brand, model) WHERE car_id IS NULL;
+CREATE INDEX IF NOT EXISTS idx_review_status ON review (status);
+
+-- Reviews belong to the tenant they were written in, including model reviews
+ALTER TABLE review ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+CREATE INDEX IF NOT EXISTS idx_review_tenant_status ON review (tenant_id, status);
+
+CREATE TABLE IF NOT EXISTS service_record (
+    id UUID PRIMARY KEY,
+    car_id UUID NOT NULL REFERENCES car(id) ON DELETE CASCADE,
+    service_date DATE NOT NULL,
+    odometer BIGINT NOT NULL CHECK (odometer >= 0),
+    work_done TEXT NOT NULL,
+    parts TEXT[] NOT NULL DEFAULT '{}',
+    cost NUMERIC(19, 2) NOT NULL,
+    currency CHAR(3) NOT NULL,
+    workshop VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_service_record_car ON service_record (car_id, service_date DESC);
+
+-- Admin-maintained depreciation curves; brand '*' is the fallback curve
+CREATE TABLE IF NOT EXISTS depreciation_curve (
+    brand VARCHAR(255) PRIMARY KEY,
+    yearly_rates NUMERIC(5, 4)[] NOT NULL,
+    annual_mileage BIGINT NOT NULL CHECK (annual_mileage > 0),
+    mileage_rate NUMERIC(5, 4) NOT NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO depreciation_curve (brand, yearly_rates, annual_mileage, mileage_rate)
+VALUES ('*', '{0.20, 0.15, 0.12, 0.10, 0.08}', 15000, 0.0100)
+ON CONFLICT (brand) DO NOTHING;
+
+-- Issued financing and lease quotes; they keep the price they were issued at
+-- and outlive the car listing
+CREATE TABLE IF NOT EXISTS quote (
+    id UUID PRIMARY KEY,
+    car_id UUID NOT NULL,
+    username VARCHAR(255) NOT NULL,
+    type VARCHAR(10) NOT NULL,
+    price NUMERIC(19, 2) NOT NULL,
+    currency CHAR(3) NOT NULL,
+    packages TEXT[] NOT NULL DEFAULT '{}',
+    down_payment NUMERIC(19, 2) NOT NULL,
+    financed NUMERIC(19, 2) NOT NULL,
+    apr NUMERIC(7, 4) NOT NULL,
+    term_months INT NOT NULL,
+    residual NUMERIC(19, 2),
+    monthly_payment NUMERIC(19, 2) NOT NULL,
+    total_interest NUMERIC(19, 2) NOT NULL,
+    total_cost NUMERIC(19, 2) NOT NULL,
+    schedule JSONB NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_quote_username ON quote (username, created_at DESC);
+
+-- Option packages offered on a single car or on every car of a trim
+CREATE TABLE IF NOT EXISTS option_package (
+    id UUID PRIMARY KEY,
+    car_id UUID REFERENCES car(id) ON DELETE CASCADE,
+    brand VARCHAR(255),
+    model VARCHAR(255),
+    code VARCHAR(32) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    price_delta NUMERIC(19, 2) NOT NULL,
+    currency CHAR(3) NOT NULL,
+    requires TEXT[] NOT NULL DEFAULT '{}',
+    excludes TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK ((car_id IS NOT NULL) <> (brand IS NOT NULL AND model IS NOT NULL))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_option_package_car ON option_package (car_id, code) WHERE car_id IS NOT NULL;
+
+-- Option packages belong to a tenant; trims of different tenants may share codes
+ALTER TABLE option_package ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+DROP INDEX IF EXISTS idx_option_package_model;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_option_package_tenant_model ON option_package (tenant_id, brand, model, code) WHERE car_id IS NULL;
+
+-- User accounts with bcrypt password hashes
+CREATE TABLE IF NOT EXISTS users (
+    id UUID PRIMARY KEY,
+    username VARCHAR(64) NOT NULL UNIQUE,
+    password_hash VARCHAR(255) NOT NULL,
+    role VARCHAR(20) NOT NULL DEFAULT 'viewer' CHECK (role IN ('viewer', 'editor', 'admin')),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Roles replace the admin flag; admins keep their access
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT 'viewer' CHECK (role IN ('viewer', 'editor', 'admin'));
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'users' AND column_name = 'is_admin') THEN
+        UPDATE users SET role = 'admin' WHERE is_admin;
+        ALTER TABLE users DROP COLUMN is_admin;
+    END IF;
+END $$;
+
+-- Accounts signed in through the OIDC issuer, keyed by its subject
+ALTER TABLE users ADD COLUMN IF NOT EXISTS oidc_subject VARCHAR(255) UNIQUE;
+
+-- The tenant a user's tokens are scoped to
+ALTER TABLE users ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+
+-- The dealership location whose cars an editor may modify
+ALTER TABLE users ADD COLUMN IF NOT EXISTS location VARCHAR(100) NOT NULL DEFAULT '';
+
+-- Requests refused because the caller's role was insufficient
+CREATE TABLE IF NOT EXISTS access_denial (
+    id UUID PRIMARY KEY,
+    username VARCHAR(64) NOT NULL,
+    role VARCHAR(20) NOT NULL,
+    required_role VARCHAR(20) NOT NULL,
+    method VARCHAR(10) NOT NULL,
+    path TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_access_denial_created_at ON access_denial (created_at DESC);
+
+-- Refresh tokens, stored as SHA-256 hashes; rotations of one login share a session_id
+CREATE TABLE IF NOT EXISTS refresh_token (
+    id UUID PRIMARY KEY,
+    session_id UUID NOT NULL,
+    username VARCHAR(64) NOT NULL,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    access_jti VARCHAR(64) NOT NULL,
+    access_expires_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    used_at TIMESTAMP,
+    revoked_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_refresh_token_session ON refresh_token (session_id);
+CREATE INDEX IF NOT EXISTS idx_refresh_token_username ON refresh_token (username);
+
+-- Access tokens revoked before they expire, keyed by their jti claim
+CREATE TABLE IF NOT EXISTS revoked_token (
+    jti VARCHAR(64) PRIMARY KEY,
+    expires_at TIMESTAMP NOT NULL
+);
+
+-- Single-use password reset tokens, stored as SHA-256 hashes
+CREATE TABLE IF NOT EXISTS password_reset_token (
+    id UUID PRIMARY KEY,
+    username VARCHAR(64) NOT NULL REFERENCES users(username) ON DELETE CASCADE,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    used_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_password_reset_token_username ON password_reset_token (username);
+
+-- API keys for machine clients, stored as SHA-256 hashes
+CREATE TABLE IF NOT EXISTS api_key (
+    id UUID PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    owner VARCHAR(64) NOT NULL REFERENCES users(username) ON DELETE CASCADE,
+    prefix VARCHAR(16) NOT NULL,
+    key_hash CHAR(64) NOT NULL UNIQUE,
+    scopes TEXT[] NOT NULL,
+    expires_at TIMESTAMP,
+    created_by VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    last_used_at TIMESTAMP,
+    revoked_at TIMESTAMP
+);
 
 -- Add foreign key constraint on engine_id in car table
 ALTER TABLE car
@@ -38,18 +352,73 @@ ALTER TABLE car
             REFERENCES engine(id)
             ON DELETE CASCADE;
 
--- Insert dummy data into the engine table
-INSERT INTO engine (id, displacement, no_of_cylinders, car_range)
+-- Row-level security backstop for tenant isolation. The car, engine, media,
+-- service record, review and option stores run their queries as carzone_app
+-- with app.tenant_id set to the request's tenant; the policies hide every
+-- other tenant's rows from that role. Rows keyed by car follow their car.
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'carzone_app') THEN
+        CREATE ROLE carzone_app NOLOGIN;
+    END IF;
+    EXECUTE format('GRANT carzone_app TO %I', current_user);
+END $$;
+GRANT SELECT, INSERT, UPDATE, DELETE ON ALL TABLES IN SCHEMA public TO carzone_app;
+GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA public TO carzone_app;
+
+ALTER TABLE car ENABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON car;
+CREATE POLICY tenant_isolation ON car TO carzone_app
+    USING (tenant_id = current_setting('app.tenant_id', true))
+    WITH CHECK (tenant_id = current_setting('app.tenant_id', true));
+
+ALTER TABLE engine ENABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON engine;
+CREATE POLICY tenant_isolation ON engine TO carzone_app
+    USING (tenant_id = current_setting('app.tenant_id', true))
+    WITH CHECK (tenant_id = current_setting('app.tenant_id', true));
+
+ALTER TABLE car_media ENABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON car_media;
+CREATE POLICY tenant_isolation ON car_media TO carzone_app
+    USING (EXISTS (SELECT 1 FROM car c WHERE c.id = car_media.car_id));
+
+ALTER TABLE service_record ENABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON service_record;
+CREATE POLICY tenant_isolation ON service_record TO carzone_app
+    USING (EXISTS (SELECT 1 FROM car c WHERE c.id = service_record.car_id));
+
+ALTER TABLE favorite ENABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON favorite;
+CREATE POLICY tenant_isolation ON favorite TO carzone_app
+    USING (EXISTS (SELECT 1 FROM car c WHERE c.id = favorite.car_id));
+
+ALTER TABLE review ENABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON review;
+CREATE POLICY tenant_isolation ON review TO carzone_app
+    USING (tenant_id = current_setting('app.tenant_id', true))
+    WITH CHECK (tenant_id = current_setting('app.tenant_id', true));
+
+ALTER TABLE option_package ENABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON option_package;
+CREATE POLICY tenant_isolation ON option_package TO carzone_app
+    USING (tenant_id = current_setting('app.tenant_id', true))
+    WITH CHECK (tenant_id = current_setting('app.tenant_id', true));
+
+-- Seed sample engines once; restarts keep existing data
+INSERT INTO engine (id, displacement, no_of_cylinders, car_range, power_kw, torque_nm, aspiration, transmission)
 VALUES
-    ('e1f86b1a-0873-4c19-bae2-fc60329d0140', 2000, 4, 600),
-    ('f4a9c66b-8e38-419b-93c4-215d5cefb318', 1600, 4, 550),
-    ('cc2c2a7d-2e21-4f59-b7b8-bd9e5e4cf04c', 3000, 6, 700),
-    ('9746be12-07b7-42a3-b8ab-7d1f209b63d7', 1800, 4, 500);
+    ('e1f86b1a-0873-4c19-bae2-fc60329d0140', 2000, 4, 600, 118.00, 192.00, 'NaturallyAspirated', 'CVT'),
+    ('f4a9c66b-8e38-419b-93c4-215d5cefb318', 1600, 4, 550, 103.00, 175.00, 'NaturallyAspirated', 'CVT'),
+    ('cc2c2a7d-2e21-4f59-b7b8-bd9e5e4cf04c', 3000, 6, 700, 235.00, 420.00, 'Turbocharged', 'Automatic'),
+    ('9746be12-07b7-42a3-b8ab-7d1f209b63d7', 1800, 4, 500, 135.00, 300.00, 'Turbocharged', 'Automatic')
+ON CONFLICT (id) DO NOTHING;
 
--- Insert dummy data into the car table
-INSERT INTO car (id, name, year, brand, fuel_type, engine_id, price)
+-- Seed sample cars once
+INSERT INTO car (id, name, year, brand, fuel_type, engine_id, price, currency)
 VALUES
-    ('c7c1a6d5-1ec4-4c64-a59a-8a2f6f3d2bf3', 'Honda Civic', '2023', 'Honda', 'Gasoline', 'e1f86b1a-0873-4c19-bae2-fc60329d0140', 25000.00),
-    ('9d6a56f8-79c3-4931-a5c0-6b290c84ba2f', 'Toyota Corolla', '2022', 'Toyota', 'Gasoline', 'f4a9c66b-8e38-419b-93c4-215d5cefb318', 22000.00),
-    ('9b9437c4-3ed1-45a5-b240-0fe3e24e0e4e', 'Ford Mustang', '2024', 'Ford', 'Gasoline', 'cc2c2a7d-2e21-4f59-b7b8-bd9e5e4cf04c', 40000.00),
-    ('5e9df51a-8d7a-4d84-9c58-4ccfe5c7db06', 'BMW 3 Series', '2023', 'BMW', 'Gasoline', '9746be12-07b7-42a3-b8ab-7d1f209b63d7', 35000.00);
\ No newline at end of file
+    ('c7c1a6d5-1ec4-4c64-a59a-8a2f6f3d2bf3', 'Honda Civic', '2023', 'Honda', 'Gasoline', 'e1f86b1a-0873-4c19-bae2-fc60329d0140', 25000.00, 'USD'),
+    ('9d6a56f8-79c3-4931-a5c0-6b290c84ba2f', 'Toyota Corolla', '2022', 'Toyota', 'Gasoline', 'f4a9c66b-8e38-419b-93c4-215d5cefb318', 22000.00, 'USD'),
+    ('9b9437c4-3ed1-45a5-b240-0fe3e24e0e4e', 'Ford Mustang', '2024', 'Ford', 'Gasoline', 'cc2c2a7d-2e21-4f59-b7b8-bd9e5e4cf04c', 40000.00, 'USD'),
+    ('5e9df51a-8d7a-4d84-9c58-4ccfe5c7db06', 'BMW 3 Series', '2023', 'BMW', 'Gasoline', '9746be12-07b7-42a3-b8ab-7d1f209b63d7', 35000.00, 'USD')
+ON CONFLICT (id) DO NOTHING;
\ No newline at end of file
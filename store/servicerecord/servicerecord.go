@@ -0,0 +1,168 @@
+package servicerecord
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/gloonch/CarZone/models"
+	"github.com/gloonch/CarZone/store"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"go.opentelemetry.io/otel"
+)
+
+type ServiceRecordStore struct {
+	db *sql.DB
+}
+
+func NewServiceRecordStore(db *sql.DB) *ServiceRecordStore {
+	return &ServiceRecordStore{
+		db: db,
+	}
+}
+
+const serviceRecordColumns = `id, car_id, service_date, odometer, work_done, parts, cost, currency, workshop, created_at`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanServiceRecord(row rowScanner) (models.ServiceRecord, error) {
+	var record models.ServiceRecord
+	var serviceDate time.Time
+	err := row.Scan(
+		&record.ID,
+		&record.CarID,
+		&serviceDate,
+		&record.Odometer,
+		&record.WorkDone,
+		pq.Array(&record.Parts),
+		&record.Cost.Amount,
+		&record.Cost.Currency,
+		&record.Workshop,
+		&record.CreatedAt,
+	)
+	if err != nil {
+		return record, err
+	}
+	record.ServiceDate = serviceDate.Format(models.DateLayout)
+	if record.Parts == nil {
+		record.Parts = []string{}
+	}
+	return record, nil
+}
+
+// queryServiceRecords runs query in a transaction of the request's tenant;
+// the tenant is passed as the last argument.
+func (s ServiceRecordStore) queryServiceRecords(ctx context.Context, query string, args ...interface{}) ([]models.ServiceRecord, error) {
+	tx, tenant, err := store.BeginTenantTx(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, query, append(args, tenant)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []models.ServiceRecord
+	for rows.Next() {
+		record, err := scanServiceRecord(rows)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// GetServiceRecords returns the service history of a car, newest first.
+func (s ServiceRecordStore) GetServiceRecords(ctx context.Context, carID string) ([]models.ServiceRecord, error) {
+	tracer := otel.Tracer("service-record-store")
+	ctx, span := tracer.Start(ctx, "GetServiceRecords-Store")
+	defer span.End()
+
+	return s.queryServiceRecords(ctx,
+		`SELECT `+serviceRecordColumns+` FROM service_record
+			WHERE car_id = $1 AND car_id IN (SELECT id FROM car WHERE tenant_id = $2)
+			ORDER BY service_date DESC, odometer DESC`, carID)
+}
+
+// LatestServiceRecords returns the most recent record of every car of the
+// tenant that has one.
+func (s ServiceRecordStore) LatestServiceRecords(ctx context.Context) ([]models.ServiceRecord, error) {
+	tracer := otel.Tracer("service-record-store")
+	ctx, span := tracer.Start(ctx, "LatestServiceRecords-Store")
+	defer span.End()
+
+	return s.queryServiceRecords(ctx,
+		`SELECT DISTINCT ON (car_id) `+serviceRecordColumns+` FROM service_record
+			WHERE car_id IN (SELECT id FROM car WHERE tenant_id = $1)
+			ORDER BY car_id, service_date DESC, odometer DESC`)
+}
+
+func (s ServiceRecordStore) CreateServiceRecord(ctx context.Context, carID string, recordReq *models.ServiceRecordRequest) (record models.ServiceRecord, err error) {
+	tracer := otel.Tracer("service-record-store")
+	ctx, span := tracer.Start(ctx, "CreateServiceRecord-Store")
+	defer span.End()
+
+	tx, tenant, err := store.BeginTenantTx(ctx, s.db)
+	if err != nil {
+		return record, err
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	row := tx.QueryRowContext(ctx,
+		`INSERT INTO service_record (id, car_id, service_date, odometer, work_done, parts, cost, currency, workshop, created_at)
+			SELECT $1, $2, $3, $4, $5, $6, $7, $8, $9, $10 WHERE EXISTS (SELECT 1 FROM car WHERE id = $2 AND tenant_id = $11)
+			RETURNING `+serviceRecordColumns,
+		uuid.New(), carID, recordReq.ServiceDate, recordReq.Odometer, recordReq.WorkDone, pq.Array(recordReq.Parts),
+		recordReq.Cost.Amount, recordReq.Cost.Currency, recordReq.Workshop, time.Now(), tenant)
+	record, err = scanServiceRecord(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return record, errors.New("car does not exist")
+	}
+	return record, err
+}
+
+func (s ServiceRecordStore) DeleteServiceRecord(ctx context.Context, carID string, id string) (record models.ServiceRecord, err error) {
+	tracer := otel.Tracer("service-record-store")
+	ctx, span := tracer.Start(ctx, "DeleteServiceRecord-Store")
+	defer span.End()
+
+	tx, tenant, err := store.BeginTenantTx(ctx, s.db)
+	if err != nil {
+		return record, err
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	row := tx.QueryRowContext(ctx,
+		`DELETE FROM service_record WHERE car_id = $1 AND car_id IN (SELECT id FROM car WHERE tenant_id = $2) AND id = $3
+			RETURNING `+serviceRecordColumns, carID, tenant, id)
+	record, err = scanServiceRecord(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return record, errors.New("service record does not exist")
+	}
+	return record, err
+}
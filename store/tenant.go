@@ -0,0 +1,32 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/gloonch/CarZone/models"
+)
+
+// BeginTenantTx starts a transaction for the tenant of the request and
+// returns the tenant. The transaction runs as the carzone_app role with
+// app.tenant_id set, so the row-level security policies on car and engine
+// hide other tenants' rows even from a query that forgets its tenant filter.
+func BeginTenantTx(ctx context.Context, db *sql.DB) (*sql.Tx, string, error) {
+	tenant := models.TenantFromContext(ctx)
+	if tenant == "" {
+		return nil, "", models.ErrNoTenant
+	}
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	_, err = tx.ExecContext(ctx, `SELECT set_config('app.tenant_id', $1, true)`, tenant)
+	if err == nil {
+		_, err = tx.ExecContext(ctx, `SET LOCAL ROLE carzone_app`)
+	}
+	if err != nil {
+		tx.Rollback()
+		return nil, "", err
+	}
+	return tx, tenant, nil
+}
@@ -0,0 +1,368 @@
+package store_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gloonch/CarZone/models"
+	"github.com/gloonch/CarZone/store"
+	carStore "github.com/gloonch/CarZone/store/car"
+	engineStore "github.com/gloonch/CarZone/store/engine"
+	mediaStore "github.com/gloonch/CarZone/store/media"
+	optionStore "github.com/gloonch/CarZone/store/option"
+	reviewStore "github.com/gloonch/CarZone/store/review"
+	serviceRecordStore "github.com/gloonch/CarZone/store/servicerecord"
+	userStore "github.com/gloonch/CarZone/store/user"
+	"github.com/google/uuid"
+	_ "github.com/lib/pq"
+)
+
+func TestBeginTenantTxRequiresTenant(t *testing.T) {
+	_, _, err := store.BeginTenantTx(context.Background(), nil)
+	if !errors.Is(err, models.ErrNoTenant) {
+		t.Fatalf("BeginTenantTx without a tenant: got %v, want %v", err, models.ErrNoTenant)
+	}
+}
+
+// testDB connects to the database named by CARZONE_TEST_DATABASE_URL and
+// applies the schema. Tests that need Postgres are skipped without it.
+func testDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	url := os.Getenv("CARZONE_TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("CARZONE_TEST_DATABASE_URL is not set")
+	}
+	db, err := sql.Open("postgres", url)
+	if err != nil {
+		t.Fatalf("opening the test database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	schema, err := os.ReadFile("schema.sql")
+	if err != nil {
+		t.Fatalf("reading the schema: %v", err)
+	}
+	if _, err := db.Exec(string(schema)); err != nil {
+		t.Fatalf("applying the schema: %v", err)
+	}
+	return db
+}
+
+// tenantFixture is a car on its own engine, with one row in every table
+// keyed by car, created in a tenant of its own.
+type tenantFixture struct {
+	ctx    context.Context
+	tenant string
+	engine models.Engine
+	car    models.Car
+	media  models.CarMedia
+	record models.ServiceRecord
+	review models.Review
+	pkg    models.OptionPackage
+	user   models.User
+	carReq models.CarRequest
+	engReq models.EngineRequest
+}
+
+type tenantStores struct {
+	cars     carStore.Store
+	engines  *engineStore.EngineStore
+	media    *mediaStore.MediaStore
+	records  *serviceRecordStore.ServiceRecordStore
+	reviews  *reviewStore.ReviewStore
+	packages *optionStore.OptionStore
+	users    *userStore.UserStore
+}
+
+func newTenantFixture(t *testing.T, db *sql.DB, stores tenantStores) tenantFixture {
+	t.Helper()
+
+	f := tenantFixture{
+		tenant: "test-" + uuid.NewString()[:8],
+	}
+	f.ctx = models.WithTenant(context.Background(), f.tenant)
+
+	var err error
+	f.engReq = models.EngineRequest{EngineType: models.EngineTypeICE, Displacement: 2000, NoOfCylinders: 4, CarRange: 600}
+	f.engine, err = stores.engines.CreateEngine(f.ctx, &f.engReq)
+	if err != nil {
+		t.Fatalf("creating an engine in %s: %v", f.tenant, err)
+	}
+
+	f.carReq = models.CarRequest{
+		Name:     "Civic",
+		Year:     "2023",
+		Brand:    "Honda",
+		FuelType: "Gasoline",
+		Engine:   models.Engine{EngineID: f.engine.EngineID},
+		Price:    models.Money{Amount: models.NewDecimal(25000), Currency: "USD"},
+		Tags:     []string{"hybrid"},
+	}
+	f.carReq.Normalize()
+	f.car, err = stores.cars.CreateCar(f.ctx, &f.carReq, "owner")
+	if err != nil {
+		t.Fatalf("creating a car in %s: %v", f.tenant, err)
+	}
+
+	f.media, err = stores.media.CreateMedia(f.ctx, &models.CarMedia{
+		ID:          uuid.New(),
+		CarID:       f.car.ID,
+		Kind:        models.MediaKindImage,
+		FileName:    "front.png",
+		ContentType: "image/png",
+		Size:        1,
+		BlobKey:     "cars/" + f.car.ID.String() + "/front",
+		CreatedAt:   time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("creating media in %s: %v", f.tenant, err)
+	}
+
+	f.record, err = stores.records.CreateServiceRecord(f.ctx, f.car.ID.String(), &models.ServiceRecordRequest{
+		ServiceDate: "2024-01-15",
+		Odometer:    1000,
+		WorkDone:    "Oil change",
+		Cost:        models.Money{Amount: models.NewDecimal(100), Currency: "USD"},
+	})
+	if err != nil {
+		t.Fatalf("creating a service record in %s: %v", f.tenant, err)
+	}
+
+	f.review, err = stores.reviews.CreateReview(f.ctx, f.tenant+"-reviewer", &models.ReviewRequest{CarID: &f.car.ID, Rating: 4})
+	if err != nil {
+		t.Fatalf("creating a review in %s: %v", f.tenant, err)
+	}
+
+	f.pkg, err = stores.packages.CreatePackage(f.ctx, &models.OptionPackageRequest{
+		CarID:      &f.car.ID,
+		Code:       "TECH",
+		Name:       "Technology",
+		PriceDelta: models.Money{Amount: models.NewDecimal(1500), Currency: "USD"},
+	})
+	if err != nil {
+		t.Fatalf("creating an option package in %s: %v", f.tenant, err)
+	}
+
+	f.user, err = stores.users.CreateUser(f.ctx, f.tenant+"-editor", "hash", models.RoleEditor, f.tenant, "")
+	if err != nil {
+		t.Fatalf("creating a user in %s: %v", f.tenant, err)
+	}
+
+	t.Cleanup(func() {
+		db.Exec("DELETE FROM car WHERE tenant_id = $1", f.tenant)
+		db.Exec("DELETE FROM engine WHERE tenant_id = $1", f.tenant)
+		db.Exec("DELETE FROM review WHERE tenant_id = $1", f.tenant)
+		db.Exec("DELETE FROM option_package WHERE tenant_id = $1", f.tenant)
+		db.Exec("DELETE FROM users WHERE tenant_id = $1", f.tenant)
+	})
+	return f
+}
+
+// TestCrossTenantIsolation creates the same data in tenants A and B and
+// checks that A can neither read, update nor delete any of B's rows.
+func TestCrossTenantIsolation(t *testing.T) {
+	db := testDB(t)
+	stores := tenantStores{
+		cars:     carStore.NewStore(db),
+		engines:  engineStore.NewEngineStore(db),
+		media:    mediaStore.NewMediaStore(db),
+		records:  serviceRecordStore.NewServiceRecordStore(db),
+		reviews:  reviewStore.NewReviewStore(db),
+		packages: optionStore.NewOptionStore(db),
+		users:    userStore.NewUserStore(db),
+	}
+	a := newTenantFixture(t, db, stores)
+	b := newTenantFixture(t, db, stores)
+	ctx := a.ctx
+	carID := b.car.ID.String()
+
+	t.Run("engines", func(t *testing.T) {
+		if _, err := stores.engines.EngineByID(ctx, b.engine.EngineID.String()); err == nil {
+			t.Error("tenant A read tenant B's engine")
+		}
+		if _, err := stores.engines.UpdateEngine(ctx, b.engine.EngineID.String(), &a.engReq); err == nil {
+			t.Error("tenant A updated tenant B's engine")
+		}
+		if _, err := stores.engines.DeleteEngine(ctx, b.engine.EngineID.String()); err == nil {
+			t.Error("tenant A deleted tenant B's engine")
+		}
+	})
+
+	t.Run("cars", func(t *testing.T) {
+		if _, err := stores.cars.GetCarByID(ctx, carID); err == nil {
+			t.Error("tenant A read tenant B's car")
+		}
+		cars, err := stores.cars.GetCarsByIDs(ctx, []string{carID})
+		if err != nil || len(cars) != 0 {
+			t.Errorf("GetCarsByIDs of tenant B's car from tenant A: got %d cars, %v", len(cars), err)
+		}
+		cars, err = stores.cars.GetAllCars(ctx)
+		if err != nil {
+			t.Fatalf("GetAllCars: %v", err)
+		}
+		for _, car := range cars {
+			if car.ID == b.car.ID {
+				t.Error("GetAllCars in tenant A listed tenant B's car")
+			}
+		}
+		cars, err = stores.cars.GetCarByBrand(ctx, models.CarQuery{Brand: "Honda"})
+		if err != nil {
+			t.Fatalf("GetCarByBrand: %v", err)
+		}
+		for _, car := range cars {
+			if car.ID == b.car.ID {
+				t.Error("GetCarByBrand in tenant A listed tenant B's car")
+			}
+		}
+		if _, err := stores.cars.UpdateCar(ctx, carID, &a.carReq, "intruder"); err == nil {
+			t.Error("tenant A updated tenant B's car")
+		}
+		if _, err := stores.cars.DeleteCar(ctx, carID); err == nil {
+			t.Error("tenant A deleted tenant B's car")
+		}
+
+		foreignEngine := a.carReq
+		foreignEngine.Engine = models.Engine{EngineID: b.engine.EngineID}
+		if _, err := stores.cars.CreateCar(ctx, &foreignEngine, "intruder"); err == nil {
+			t.Error("tenant A created a car on tenant B's engine")
+		}
+	})
+
+	t.Run("media", func(t *testing.T) {
+		media, err := stores.media.GetMediaByCar(ctx, carID)
+		if err != nil || len(media) != 0 {
+			t.Errorf("GetMediaByCar of tenant B's car from tenant A: got %d media, %v", len(media), err)
+		}
+		if _, err := stores.media.MediaByID(ctx, carID, b.media.ID.String()); err == nil {
+			t.Error("tenant A read tenant B's media")
+		}
+		if _, err := stores.media.DeleteMedia(ctx, carID, b.media.ID.String()); err == nil {
+			t.Error("tenant A deleted tenant B's media")
+		}
+		_, err = stores.media.CreateMedia(ctx, &models.CarMedia{
+			ID: uuid.New(), CarID: b.car.ID, Kind: models.MediaKindImage, FileName: "x.png",
+			ContentType: "image/png", Size: 1, BlobKey: "x", CreatedAt: time.Now(),
+		})
+		if err == nil {
+			t.Error("tenant A attached media to tenant B's car")
+		}
+	})
+
+	t.Run("service records", func(t *testing.T) {
+		records, err := stores.records.GetServiceRecords(ctx, carID)
+		if err != nil || len(records) != 0 {
+			t.Errorf("GetServiceRecords of tenant B's car from tenant A: got %d records, %v", len(records), err)
+		}
+		latest, err := stores.records.LatestServiceRecords(ctx)
+		if err != nil {
+			t.Fatalf("LatestServiceRecords: %v", err)
+		}
+		for _, record := range latest {
+			if record.CarID == b.car.ID {
+				t.Error("LatestServiceRecords in tenant A listed tenant B's car")
+			}
+		}
+		if _, err := stores.records.DeleteServiceRecord(ctx, carID, b.record.ID.String()); err == nil {
+			t.Error("tenant A deleted tenant B's service record")
+		}
+		_, err = stores.records.CreateServiceRecord(ctx, carID, &models.ServiceRecordRequest{
+			ServiceDate: "2024-02-01", Odometer: 2000, WorkDone: "Brakes",
+			Cost: models.Money{Amount: models.NewDecimal(50), Currency: "USD"},
+		})
+		if err == nil {
+			t.Error("tenant A added a service record to tenant B's car")
+		}
+	})
+
+	t.Run("reviews", func(t *testing.T) {
+		pending, err := stores.reviews.GetReviewsByStatus(ctx, models.ReviewStatusPending)
+		if err != nil {
+			t.Fatalf("GetReviewsByStatus: %v", err)
+		}
+		for _, review := range pending {
+			if review.ID == b.review.ID {
+				t.Error("the moderation queue of tenant A listed tenant B's review")
+			}
+		}
+		if _, err := stores.reviews.UpdateReviewStatus(ctx, b.review.ID.String(), models.ReviewStatusApproved); err == nil {
+			t.Error("tenant A moderated tenant B's review")
+		}
+		if _, err := stores.reviews.DeleteReview(ctx, b.review.Username, b.review.ID.String()); err == nil {
+			t.Error("tenant A deleted tenant B's review")
+		}
+		if _, err := stores.reviews.CreateReview(ctx, a.tenant+"-intruder", &models.ReviewRequest{CarID: &b.car.ID, Rating: 1}); err == nil {
+			t.Error("tenant A reviewed tenant B's car")
+		}
+	})
+
+	t.Run("option packages", func(t *testing.T) {
+		packages, err := stores.packages.GetPackagesForCar(ctx, carID)
+		if err != nil || len(packages) != 0 {
+			t.Errorf("GetPackagesForCar of tenant B's car from tenant A: got %d packages, %v", len(packages), err)
+		}
+		if _, err := stores.packages.DeletePackage(ctx, b.pkg.ID.String()); err == nil {
+			t.Error("tenant A deleted tenant B's option package")
+		}
+	})
+
+	t.Run("users", func(t *testing.T) {
+		users, err := stores.users.GetUsers(ctx, a.tenant)
+		if err != nil {
+			t.Fatalf("GetUsers: %v", err)
+		}
+		for _, user := range users {
+			if user.Username == b.user.Username {
+				t.Error("GetUsers in tenant A listed tenant B's user")
+			}
+		}
+	})
+
+	t.Run("row-level security", func(t *testing.T) {
+		tx, _, err := store.BeginTenantTx(ctx, db)
+		if err != nil {
+			t.Fatalf("BeginTenantTx: %v", err)
+		}
+		defer tx.Rollback()
+
+		// No tenant filter: only the policies stand between A and B's rows.
+		for _, query := range []string{
+			"SELECT COUNT(*) FROM car WHERE id = $1",
+			"SELECT COUNT(*) FROM engine WHERE id = (SELECT engine_id FROM car WHERE id = $1)",
+			"SELECT COUNT(*) FROM car_media WHERE car_id = $1",
+			"SELECT COUNT(*) FROM service_record WHERE car_id = $1",
+			"SELECT COUNT(*) FROM review WHERE car_id = $1",
+			"SELECT COUNT(*) FROM option_package WHERE car_id = $1",
+		} {
+			var count int
+			if err := tx.QueryRowContext(ctx, query, carID).Scan(&count); err != nil {
+				t.Fatalf("%s: %v", query, err)
+			}
+			if count != 0 {
+				t.Errorf("%s: tenant A sees %d of tenant B's rows", query, count)
+			}
+		}
+	})
+
+	t.Run("tenant B keeps its rows", func(t *testing.T) {
+		if _, err := stores.cars.GetCarByID(b.ctx, carID); err != nil {
+			t.Errorf("tenant B cannot read its own car: %v", err)
+		}
+		if _, err := stores.engines.EngineByID(b.ctx, b.engine.EngineID.String()); err != nil {
+			t.Errorf("tenant B cannot read its own engine: %v", err)
+		}
+		if media, err := stores.media.GetMediaByCar(b.ctx, carID); err != nil || len(media) != 1 {
+			t.Errorf("tenant B's media: got %d, %v", len(media), err)
+		}
+		if records, err := stores.records.GetServiceRecords(b.ctx, carID); err != nil || len(records) != 1 {
+			t.Errorf("tenant B's service records: got %d, %v", len(records), err)
+		}
+		if packages, err := stores.packages.GetPackagesForCar(b.ctx, carID); err != nil || len(packages) != 1 {
+			t.Errorf("tenant B's option packages: got %d, %v", len(packages), err)
+		}
+	})
+}
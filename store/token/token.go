@@ -0,0 +1,161 @@
+package token
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/gloonch/CarZone/models"
+	"go.opentelemetry.io/otel"
+)
+
+type TokenStore struct {
+	db *sql.DB
+}
+
+func NewTokenStore(db *sql.DB) *TokenStore {
+	return &TokenStore{
+		db: db,
+	}
+}
+
+const refreshTokenColumns = `id, session_id, username, token_hash, access_jti, access_expires_at,
+	expires_at, created_at, used_at, revoked_at`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanRefreshToken(row rowScanner) (models.RefreshToken, error) {
+	var token models.RefreshToken
+	err := row.Scan(
+		&token.ID,
+		&token.SessionID,
+		&token.Username,
+		&token.TokenHash,
+		&token.AccessJTI,
+		&token.AccessExpiresAt,
+		&token.ExpiresAt,
+		&token.CreatedAt,
+		&token.UsedAt,
+		&token.RevokedAt,
+	)
+	return token, err
+}
+
+func (s TokenStore) CreateRefreshToken(ctx context.Context, token models.RefreshToken) error {
+	tracer := otel.Tracer("token-store")
+	ctx, span := tracer.Start(ctx, "CreateRefreshToken-Store")
+	defer span.End()
+
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO refresh_token (`+refreshTokenColumns+`)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+		token.ID, token.SessionID, token.Username, token.TokenHash, token.AccessJTI, token.AccessExpiresAt,
+		token.ExpiresAt, token.CreatedAt, token.UsedAt, token.RevokedAt)
+	return err
+}
+
+// UseRefreshToken marks the token with the given hash as used so it cannot be
+// exchanged twice. A token that was already used is returned together with
+// models.ErrRefreshTokenReused; unknown, expired and revoked tokens give
+// models.ErrInvalidRefreshToken.
+func (s TokenStore) UseRefreshToken(ctx context.Context, tokenHash string, now time.Time) (models.RefreshToken, error) {
+	tracer := otel.Tracer("token-store")
+	ctx, span := tracer.Start(ctx, "UseRefreshToken-Store")
+	defer span.End()
+
+	row := s.db.QueryRowContext(ctx,
+		`UPDATE refresh_token SET used_at = $2
+			WHERE token_hash = $1 AND used_at IS NULL AND revoked_at IS NULL AND expires_at > $2
+			RETURNING `+refreshTokenColumns,
+		tokenHash, now)
+	token, err := scanRefreshToken(row)
+	if err == nil {
+		return token, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return token, err
+	}
+
+	row = s.db.QueryRowContext(ctx, `SELECT `+refreshTokenColumns+` FROM refresh_token WHERE token_hash = $1`, tokenHash)
+	token, err = scanRefreshToken(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return token, models.ErrInvalidRefreshToken
+		}
+		return token, err
+	}
+	if token.UsedAt != nil && token.RevokedAt == nil {
+		return token, models.ErrRefreshTokenReused
+	}
+	return token, models.ErrInvalidRefreshToken
+}
+
+// RevokeSession revokes every refresh token of the session and puts the
+// access tokens issued with them on the revocation list.
+func (s TokenStore) RevokeSession(ctx context.Context, sessionID string, now time.Time) error {
+	tracer := otel.Tracer("token-store")
+	ctx, span := tracer.Start(ctx, "RevokeSession-Store")
+	defer span.End()
+
+	return s.revoke(ctx, "session_id", sessionID, now)
+}
+
+// RevokeUserSessions revokes all sessions of a user.
+func (s TokenStore) RevokeUserSessions(ctx context.Context, username string, now time.Time) error {
+	tracer := otel.Tracer("token-store")
+	ctx, span := tracer.Start(ctx, "RevokeUserSessions-Store")
+	defer span.End()
+
+	return s.revoke(ctx, "username", username, now)
+}
+
+func (s TokenStore) revoke(ctx context.Context, column string, value string, now time.Time) (err error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO revoked_token (jti, expires_at)
+			SELECT access_jti, access_expires_at FROM refresh_token
+			WHERE `+column+` = $1 AND access_expires_at > $2
+			ON CONFLICT (jti) DO NOTHING`,
+		value, now)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx,
+		`UPDATE refresh_token SET revoked_at = $2 WHERE `+column+` = $1 AND revoked_at IS NULL`,
+		value, now)
+	if err != nil {
+		return err
+	}
+
+	// Expired entries can no longer be used, so neither list needs them.
+	_, err = tx.ExecContext(ctx, `DELETE FROM revoked_token WHERE expires_at <= $1`, now)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `DELETE FROM refresh_token WHERE expires_at <= $1`, now)
+	return err
+}
+
+func (s TokenStore) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	tracer := otel.Tracer("token-store")
+	ctx, span := tracer.Start(ctx, "IsRevoked-Store")
+	defer span.End()
+
+	var revoked bool
+	err := s.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM revoked_token WHERE jti = $1)`, jti).Scan(&revoked)
+	return revoked, err
+}
@@ -0,0 +1,168 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/gloonch/CarZone/models"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"go.opentelemetry.io/otel"
+)
+
+type UserStore struct {
+	db *sql.DB
+}
+
+func NewUserStore(db *sql.DB) *UserStore {
+	return &UserStore{
+		db: db,
+	}
+}
+
+const userColumns = `id, username, password_hash, role, tenant_id, location, created_at, updated_at`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanUser(row rowScanner) (models.User, error) {
+	var user models.User
+	err := row.Scan(
+		&user.ID,
+		&user.Username,
+		&user.PasswordHash,
+		&user.Role,
+		&user.TenantID,
+		&user.Location,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
+	return user, err
+}
+
+func (s UserStore) GetUserByUsername(ctx context.Context, username string) (models.User, error) {
+	tracer := otel.Tracer("user-store")
+	ctx, span := tracer.Start(ctx, "GetUserByUsername-Store")
+	defer span.End()
+
+	row := s.db.QueryRowContext(ctx, `SELECT `+userColumns+` FROM users WHERE username = $1`, username)
+	user, err := scanUser(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return user, models.ErrUserNotFound
+		}
+		return user, err
+	}
+	return user, nil
+}
+
+// GetUsers lists the users of a tenant.
+func (s UserStore) GetUsers(ctx context.Context, tenantID string) ([]models.User, error) {
+	tracer := otel.Tracer("user-store")
+	ctx, span := tracer.Start(ctx, "GetUsers-Store")
+	defer span.End()
+
+	rows, err := s.db.QueryContext(ctx, `SELECT `+userColumns+` FROM users WHERE tenant_id = $1 ORDER BY username`, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []models.User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+func (s UserStore) CountUsers(ctx context.Context) (int64, error) {
+	tracer := otel.Tracer("user-store")
+	ctx, span := tracer.Start(ctx, "CountUsers-Store")
+	defer span.End()
+
+	var count int64
+	err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM users`).Scan(&count)
+	return count, err
+}
+
+// CreateUser stores a user of a tenant with an already hashed password.
+func (s UserStore) CreateUser(ctx context.Context, username string, passwordHash string, role string, tenantID string, location string) (models.User, error) {
+	tracer := otel.Tracer("user-store")
+	ctx, span := tracer.Start(ctx, "CreateUser-Store")
+	defer span.End()
+
+	now := time.Now()
+	row := s.db.QueryRowContext(ctx,
+		`INSERT INTO users (id, username, password_hash, role, tenant_id, location, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			RETURNING `+userColumns,
+		uuid.New(), username, passwordHash, role, tenantID, location, now, now)
+	user, err := scanUser(row)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return user, errors.New("username is already taken")
+		}
+		return user, err
+	}
+	return user, nil
+}
+
+// UpdatePassword replaces a user's password with an already hashed one.
+func (s UserStore) UpdatePassword(ctx context.Context, username string, passwordHash string) error {
+	tracer := otel.Tracer("user-store")
+	ctx, span := tracer.Start(ctx, "UpdatePassword-Store")
+	defer span.End()
+
+	result, err := s.db.ExecContext(ctx,
+		`UPDATE users SET password_hash = $2, updated_at = $3 WHERE username = $1`,
+		username, passwordHash, time.Now())
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return models.ErrUserNotFound
+	}
+	return nil
+}
+
+// UpsertOIDCUser returns the account linked to an OIDC subject, creating it
+// on first login. The role is updated on every login so group changes at the
+// issuer take effect; the tenant is only set on creation. SSO accounts have
+// no password and cannot log in locally.
+func (s UserStore) UpsertOIDCUser(ctx context.Context, subject string, username string, role string, tenantID string) (models.User, error) {
+	tracer := otel.Tracer("user-store")
+	ctx, span := tracer.Start(ctx, "UpsertOIDCUser-Store")
+	defer span.End()
+
+	now := time.Now()
+	row := s.db.QueryRowContext(ctx,
+		`INSERT INTO users (id, username, password_hash, role, tenant_id, oidc_subject, created_at, updated_at)
+			VALUES ($1, $2, '', $3, $4, $5, $6, $6)
+			ON CONFLICT (oidc_subject) DO UPDATE SET role = EXCLUDED.role, updated_at = EXCLUDED.updated_at
+			RETURNING `+userColumns,
+		uuid.New(), username, role, tenantID, subject, now)
+	user, err := scanUser(row)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return user, models.ErrUsernameTaken
+		}
+		return user, err
+	}
+	return user, nil
+}
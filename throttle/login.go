@@ -0,0 +1,275 @@
+// Package throttle slows down password guessing against the login endpoint.
+package throttle
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// maxEntries bounds the memory used for tracking; once reached and nothing
+// idle can be pruned, attempts for new keys are refused for pruneInterval
+// rather than let through untracked.
+const maxEntries = 100000
+
+// pruneInterval is how often forgotten entries are dropped.
+const pruneInterval = time.Minute
+
+var (
+	failedLoginCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "login_failures_total",
+			Help: "Total number of failed logins by reason",
+		},
+		[]string{"reason"},
+	)
+
+	lockoutCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "login_lockouts_total",
+			Help: "Total number of times a username or IP address was locked out",
+		},
+		[]string{"scope"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(failedLoginCounter, lockoutCounter)
+}
+
+// Policy configures the backoff for one kind of key. The first FreeAttempts
+// failures cost nothing; each further failure blocks the key for BaseDelay,
+// doubling up to MaxDelay, which acts as a temporary lockout. A key is
+// forgotten ResetAfter its last failure.
+type Policy struct {
+	FreeAttempts int
+	BaseDelay    time.Duration
+	MaxDelay     time.Duration
+	ResetAfter   time.Duration
+}
+
+var (
+	// UsernamePolicy protects a single account against guessing.
+	UsernamePolicy = Policy{FreeAttempts: 3, BaseDelay: time.Second, MaxDelay: 15 * time.Minute, ResetAfter: time.Hour}
+	// IPPolicy protects against one client trying many accounts; it is
+	// looser because offices and NATs share addresses.
+	IPPolicy = Policy{FreeAttempts: 10, BaseDelay: time.Second, MaxDelay: 15 * time.Minute, ResetAfter: time.Hour}
+)
+
+type entry struct {
+	failures     int
+	inFlight     int
+	lastFailure  time.Time
+	blockedUntil time.Time
+}
+
+type tracker struct {
+	scope   string
+	policy  Policy
+	entries map[string]*entry
+}
+
+func newTracker(scope string, policy Policy) *tracker {
+	return &tracker{scope: scope, policy: policy, entries: map[string]*entry{}}
+}
+
+// retryAfter returns how long an attempt for key has to wait. Attempts that
+// are still in flight count as failures, so once the free attempts are used
+// up a key only gets one attempt at a time and each waits for the backoff of
+// the previous one.
+func (t *tracker) retryAfter(key string, now time.Time) time.Duration {
+	e, ok := t.entries[key]
+	if !ok {
+		return 0
+	}
+	if now.Before(e.blockedUntil) {
+		return e.blockedUntil.Sub(now)
+	}
+	failures := e.failures
+	if now.Sub(e.lastFailure) >= t.policy.ResetAfter {
+		failures = 0
+	}
+	if e.inFlight > 0 && failures+e.inFlight >= t.policy.FreeAttempts {
+		return t.policy.BaseDelay
+	}
+	return 0
+}
+
+// full reports whether key is new and there is no room to track it.
+func (t *tracker) full(key string) bool {
+	_, ok := t.entries[key]
+	return !ok && len(t.entries) >= maxEntries
+}
+
+// reserve records an attempt for key that is in flight.
+func (t *tracker) reserve(key string) {
+	e, ok := t.entries[key]
+	if !ok {
+		if len(t.entries) >= maxEntries {
+			return
+		}
+		e = &entry{}
+		t.entries[key] = e
+	}
+	e.inFlight++
+}
+
+// release ends an attempt recorded by reserve.
+func (t *tracker) release(key string) {
+	if e, ok := t.entries[key]; ok && e.inFlight > 0 {
+		e.inFlight--
+	}
+}
+
+func (t *tracker) fail(key string, now time.Time) time.Duration {
+	t.release(key)
+	e, ok := t.entries[key]
+	if !ok {
+		if len(t.entries) >= maxEntries {
+			return 0
+		}
+		e = &entry{}
+		t.entries[key] = e
+	}
+	if now.Sub(e.lastFailure) >= t.policy.ResetAfter {
+		e.failures = 0
+	}
+	e.failures++
+	e.lastFailure = now
+
+	excess := e.failures - t.policy.FreeAttempts
+	if excess <= 0 {
+		return 0
+	}
+	delay := t.policy.MaxDelay
+	if excess < 32 {
+		delay = time.Duration(math.Min(float64(t.policy.BaseDelay)*math.Pow(2, float64(excess-1)), float64(t.policy.MaxDelay)))
+	}
+	if delay == t.policy.MaxDelay && now.After(e.blockedUntil) {
+		lockoutCounter.WithLabelValues(t.scope).Inc()
+	}
+	e.blockedUntil = now.Add(delay)
+	return delay
+}
+
+func (t *tracker) prune(now time.Time) {
+	for key, e := range t.entries {
+		if e.inFlight == 0 && now.Sub(e.lastFailure) >= t.policy.ResetAfter && !now.Before(e.blockedUntil) {
+			delete(t.entries, key)
+		}
+	}
+}
+
+// LoginThrottle tracks failed logins per username and per client IP address
+// in memory.
+type LoginThrottle struct {
+	// TrustForwardedFor takes the client IP from X-Forwarded-For. Only set
+	// it behind a proxy that overwrites the header, or clients can pick
+	// their own address.
+	TrustForwardedFor bool
+
+	mu        sync.Mutex
+	users     *tracker
+	ips       *tracker
+	lastPrune time.Time
+}
+
+func NewLoginThrottle(usernamePolicy Policy, ipPolicy Policy) *LoginThrottle {
+	return &LoginThrottle{
+		users: newTracker("username", usernamePolicy),
+		ips:   newTracker("ip", ipPolicy),
+	}
+}
+
+// ClientIP returns the IP address attempts from r are tracked under.
+func (t *LoginThrottle) ClientIP(r *http.Request) string {
+	if t.TrustForwardedFor {
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			first, _, _ := strings.Cut(forwarded, ",")
+			return strings.TrimSpace(first)
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// Allow returns how long the caller must wait before trying to log in as
+// username from ip, or zero if the attempt may go ahead. An allowed attempt
+// is reserved until the caller reports its outcome with Failure, Success or
+// Cancel, so concurrent guesses cannot slip past the backoff.
+func (t *LoginThrottle) Allow(username string, ip string) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	wait := max(t.users.retryAfter(username, now), t.ips.retryAfter(ip, now))
+	if wait > 0 {
+		failedLoginCounter.WithLabelValues("throttled").Inc()
+		return wait
+	}
+	if t.users.full(username) || t.ips.full(ip) {
+		t.pruneIfDue(now)
+		if t.users.full(username) || t.ips.full(ip) {
+			failedLoginCounter.WithLabelValues("throttle_full").Inc()
+			return pruneInterval
+		}
+	}
+	t.users.reserve(username)
+	t.ips.reserve(ip)
+	return 0
+}
+
+// Failure records a failed login allowed by Allow and returns how long
+// further attempts are now blocked for, or zero.
+func (t *LoginThrottle) Failure(username string, ip string) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	failedLoginCounter.WithLabelValues("invalid_credentials").Inc()
+	t.pruneIfDue(now)
+	return max(t.users.fail(username, now), t.ips.fail(ip, now))
+}
+
+// pruneIfDue drops forgotten entries at most once per pruneInterval.
+func (t *LoginThrottle) pruneIfDue(now time.Time) {
+	if now.Sub(t.lastPrune) >= pruneInterval {
+		t.users.prune(now)
+		t.ips.prune(now)
+		t.lastPrune = now
+	}
+}
+
+// Success forgets the failures of username. The IP address keeps its count,
+// so one valid account cannot be used to reset guessing against others.
+func (t *LoginThrottle) Success(username string, ip string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.users.release(username)
+	if e, ok := t.users.entries[username]; ok && e.inFlight == 0 {
+		delete(t.users.entries, username)
+	} else if ok {
+		e.failures = 0
+		e.blockedUntil = time.Time{}
+	}
+	t.ips.release(ip)
+}
+
+// Cancel ends an attempt allowed by Allow that neither succeeded nor failed,
+// such as one that hit a server error.
+func (t *LoginThrottle) Cancel(username string, ip string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.users.release(username)
+	t.ips.release(ip)
+}
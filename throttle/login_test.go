@@ -0,0 +1,118 @@
+package throttle
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+var testPolicy = Policy{FreeAttempts: 2, BaseDelay: time.Minute, MaxDelay: time.Hour, ResetAfter: time.Hour}
+
+// looseIPs keeps the IP address tracker out of the way of username tests.
+var looseIPs = Policy{FreeAttempts: 1000, BaseDelay: time.Minute, MaxDelay: time.Hour, ResetAfter: time.Hour}
+
+func TestAllowCountsAttemptsInFlight(t *testing.T) {
+	throttle := NewLoginThrottle(testPolicy, looseIPs)
+
+	if wait := throttle.Allow("alice", "10.0.0.1"); wait != 0 {
+		t.Fatalf("first attempt: wait %s", wait)
+	}
+	if wait := throttle.Allow("alice", "10.0.0.2"); wait != 0 {
+		t.Fatalf("second attempt: wait %s", wait)
+	}
+	if wait := throttle.Allow("alice", "10.0.0.3"); wait == 0 {
+		t.Fatal("a third concurrent attempt was allowed with two free attempts")
+	}
+
+	throttle.Failure("alice", "10.0.0.1")
+	throttle.Failure("alice", "10.0.0.2")
+	if wait := throttle.Allow("alice", "10.0.0.4"); wait != 0 {
+		t.Fatalf("attempt after the free ones: wait %s", wait)
+	}
+	if wait := throttle.Allow("alice", "10.0.0.5"); wait == 0 {
+		t.Fatal("a concurrent attempt was allowed after the free attempts were used up")
+	}
+	if wait := throttle.Failure("alice", "10.0.0.4"); wait != testPolicy.BaseDelay {
+		t.Fatalf("third failure: blocked for %s, want %s", wait, testPolicy.BaseDelay)
+	}
+	if wait := throttle.Allow("alice", "10.0.0.6"); wait <= 0 || wait > testPolicy.BaseDelay {
+		t.Fatalf("attempt during the backoff: wait %s", wait)
+	}
+}
+
+func TestConcurrentGuessesCannotBypassBackoff(t *testing.T) {
+	throttle := NewLoginThrottle(testPolicy, looseIPs)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	allowed := 0
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if throttle.Allow("alice", fmt.Sprintf("10.0.0.%d", i)) == 0 {
+				mu.Lock()
+				allowed++
+				mu.Unlock()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if allowed != testPolicy.FreeAttempts {
+		t.Fatalf("%d concurrent attempts were allowed, want %d", allowed, testPolicy.FreeAttempts)
+	}
+}
+
+func TestSuccessAndCancelReleaseTheAttempt(t *testing.T) {
+	throttle := NewLoginThrottle(testPolicy, testPolicy)
+
+	throttle.Allow("alice", "10.0.0.1")
+	throttle.Failure("alice", "10.0.0.1")
+	throttle.Allow("alice", "10.0.0.1")
+	throttle.Success("alice", "10.0.0.1")
+	if _, ok := throttle.users.entries["alice"]; ok {
+		t.Fatal("a successful login kept the username's failures")
+	}
+	if e := throttle.ips.entries["10.0.0.1"]; e == nil || e.failures != 1 || e.inFlight != 0 {
+		t.Fatalf("IP address after a successful login: %+v, want one failure and nothing in flight", e)
+	}
+
+	throttle.Allow("bob", "10.0.0.2")
+	throttle.Cancel("bob", "10.0.0.2")
+	for _, tracker := range []*tracker{throttle.users, throttle.ips} {
+		for key, e := range tracker.entries {
+			if e.inFlight != 0 {
+				t.Fatalf("%s still has %d attempts in flight", key, e.inFlight)
+			}
+		}
+	}
+}
+
+func TestFullTrackerRefusesNewKeys(t *testing.T) {
+	throttle := NewLoginThrottle(testPolicy, looseIPs)
+	now := time.Now()
+	for i := 0; i < maxEntries; i++ {
+		throttle.users.entries[fmt.Sprintf("user-%d", i)] = &entry{failures: 1, lastFailure: now}
+	}
+
+	if wait := throttle.Allow("user-1", "10.0.0.1"); wait != 0 {
+		t.Fatalf("a tracked username was refused: wait %s", wait)
+	}
+	if wait := throttle.Allow("mallory", "10.0.0.2"); wait == 0 {
+		t.Fatal("an untracked username was let through while the tracker was full")
+	}
+	if _, ok := throttle.users.entries["mallory"]; ok {
+		t.Fatal("the tracker grew past maxEntries")
+	}
+
+	// Once the entries are forgotten, pruning makes room again.
+	for _, e := range throttle.users.entries {
+		e.lastFailure = now.Add(-2 * testPolicy.ResetAfter)
+	}
+	throttle.lastPrune = time.Time{}
+	if wait := throttle.Allow("mallory", "10.0.0.2"); wait != 0 {
+		t.Fatalf("a new username was refused after pruning: wait %s", wait)
+	}
+}